@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg"
+	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// 定义 apply-settings 命令的参数变量
+var (
+	applySettingsGroup   string
+	applySettingsProject string
+	applySettingsInput   string
+)
+
+// applySettingsCmd 定义了 'apply-settings' 子命令，将 'export-settings' 导出的 YAML 快照
+// 应用到 --group 下的 --project，用于迁移设置或从备份恢复。已存在的变量/成员按 key/username
+// 跳过而不覆盖，其余类别失败的条目记录为警告后继续处理其余条目，不会因单条失败而中断整体应用。
+var applySettingsCmd = &cobra.Command{
+	Use:   "apply-settings",
+	Short: "将 export-settings 导出的 YAML 快照应用到另一个项目",
+	Long: `读取 --input 指定的快照 (由 'export-settings' 生成)，将其中的 CI/CD 变量、受保护分支、
+webhooks、push rules、成员应用到 --group 下的 --project。
+
+已存在同名的变量/成员会被跳过而不覆盖，避免误覆盖目标项目已有的配置；其余类别
+(受保护分支/webhooks/push rules) 直接创建，单条失败会记录为警告而不中断其余条目的应用。`,
+	Example: `  gitlab-fork-cli apply-settings --group fy-prod --project iris --input iris-settings.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if applySettingsGroup == "" || applySettingsProject == "" || applySettingsInput == "" {
+			logFatal("❌ 错误: 必须提供 --group、--project 和 --input 参数。")
+		}
+
+		ctx := cmd.Context()
+		data, err := readSource(ctx, applySettingsInput)
+		if err != nil {
+			logFatalf("❌ 读取快照 '%s' 失败: %v\n", applySettingsInput, err)
+		}
+		var snapshot pkg.SettingsSnapshot
+		if err := yaml.Unmarshal(data, &snapshot); err != nil {
+			logFatalf("❌ 解析快照 '%s' 失败: %v\n", applySettingsInput, err)
+		}
+
+		kubeRestConfig, err := k8sutil.GetKubeConfig()
+		if err != nil {
+			logFatalf("❌ 无法获取 Kubernetes 配置。错误: %v\n", err)
+		}
+
+		token, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, applySettingsGroup, GitlabSecretName, GitlabTokenKey)
+		if err != nil {
+			logFatalf("❌ 无法获取组 '%s' 的令牌。错误: %v\n", applySettingsGroup, err)
+		}
+		git, err := newGitLabClient(token, baseURL, insecureSkip, caCertFile)
+		if err != nil {
+			logFatalf("❌ 创建 GitLab 客户端失败: %v\n", err)
+		}
+
+		groupPath := getModelGroupByNs(applySettingsGroup)
+		projectID, err := findProjectInGroup(git, groupPath, applySettingsProject, groupEnumFilter{includeArchived: true})
+		if err != nil {
+			logFatalf("❌ 在组 '%s' 中未找到项目 '%s'。错误: %v\n", groupPath, applySettingsProject, err)
+		}
+
+		if readOnlyGuard(fmt.Sprintf("将快照 '%s' (来源项目: %s) 应用到 '%s/%s'", applySettingsInput, snapshot.Project, groupPath, applySettingsProject)) {
+			return
+		}
+
+		warnings := pkg.NewWarningCollector()
+		warnings.SetStrict(strictMode)
+		warnings.SetCorrelationID(correlationID)
+		defer warnings.PrintSummary()
+
+		log.Printf("🚀 正在将快照 (来源项目: %s) 应用到 '%s/%s' (ID: %d)...\n", snapshot.Project, groupPath, applySettingsProject, projectID)
+		if err := pkg.ApplySettingsSnapshot(ctx, git, projectID, &snapshot, warnings); err != nil {
+			logFatalf("❌ 应用快照失败: %v\n", err)
+		}
+		log.Println("✅ 快照应用完成。")
+	},
+}
+
+func init() {
+	applySettingsCmd.Flags().StringVar(&applySettingsGroup, "group", "", "目标项目所在的 NS 名称 (必填)")
+	applySettingsCmd.Flags().StringVar(&applySettingsProject, "project", "", "要应用设置的目标项目名称 (必填)")
+	applySettingsCmd.Flags().StringVar(&applySettingsInput, "input", "", "要应用的快照文件路径 (必填)，除本地文件路径外还支持 '-' (标准输入)、'http(s)://' URL、'configmap://<namespace>/<name>/<key>'")
+
+	applySettingsCmd.MarkFlagRequired("group")
+	applySettingsCmd.MarkFlagRequired("project")
+	applySettingsCmd.MarkFlagRequired("input")
+
+	rootCmd.AddCommand(applySettingsCmd)
+}
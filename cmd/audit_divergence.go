@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
+	"github.com/spf13/cobra"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// 定义 audit-divergence 命令的参数变量
+var (
+	auditGroup     string
+	auditThreshold time.Duration
+)
+
+// divergenceReport 描述单个 fork 相对其上游的落后情况，用于序列化为告警流水线可消费的 JSON
+type divergenceReport struct {
+	Project                 string  `json:"project"`
+	ProjectID               int     `json:"project_id"`
+	Upstream                string  `json:"upstream"`
+	UpstreamID              int     `json:"upstream_id"`
+	DefaultBranchBehind     bool    `json:"default_branch_behind"`
+	DefaultBranchLagSeconds float64 `json:"default_branch_lag_seconds"`
+	LatestTagBehind         bool    `json:"latest_tag_behind"`
+	LatestTagLagSeconds     float64 `json:"latest_tag_lag_seconds,omitempty"`
+	Error                   string  `json:"error,omitempty"`
+}
+
+// latestTagCommitDate 返回项目下所有标签中最新的提交时间，没有任何标签时返回 nil
+func latestTagCommitDate(client *gitlab.Client, projectID int) (*time.Time, error) {
+	listOptions := &gitlab.ListTagsOptions{}
+	listOptions.PerPage = 100
+
+	var latest *time.Time
+	for {
+		tags, resp, err := client.Tags.ListTags(projectID, listOptions)
+		if err != nil {
+			return nil, fmt.Errorf("列出项目 (ID: %d) 的标签失败: %w", projectID, err)
+		}
+		for _, tag := range tags {
+			if tag.Commit == nil || tag.Commit.CommittedDate == nil {
+				continue
+			}
+			if latest == nil || tag.Commit.CommittedDate.After(*latest) {
+				latest = tag.Commit.CommittedDate
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		listOptions.Page = resp.NextPage
+	}
+	return latest, nil
+}
+
+// checkForkDivergence 比较 fork 与其上游项目的默认分支最新提交时间、最新标签时间，
+// 落后超过 threshold 时在对应字段上标记为 true。
+func checkForkDivergence(client *gitlab.Client, fork *gitlab.Project, threshold time.Duration) (*divergenceReport, error) {
+	report := &divergenceReport{
+		Project:    fork.PathWithNamespace,
+		ProjectID:  fork.ID,
+		Upstream:   fork.ForkedFromProject.PathWithNamespace,
+		UpstreamID: fork.ForkedFromProject.ID,
+	}
+
+	upstream, _, err := client.Projects.GetProject(fork.ForkedFromProject.ID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取上游项目 '%s' 失败: %w", fork.ForkedFromProject.PathWithNamespace, err)
+	}
+
+	forkBranch, _, err := client.Branches.GetBranch(fork.ID, fork.DefaultBranch)
+	if err != nil {
+		return nil, fmt.Errorf("获取 fork '%s' 的默认分支 '%s' 失败: %w", fork.PathWithNamespace, fork.DefaultBranch, err)
+	}
+	upstreamBranch, _, err := client.Branches.GetBranch(upstream.ID, upstream.DefaultBranch)
+	if err != nil {
+		return nil, fmt.Errorf("获取上游 '%s' 的默认分支 '%s' 失败: %w", upstream.PathWithNamespace, upstream.DefaultBranch, err)
+	}
+	if forkBranch.Commit != nil && forkBranch.Commit.CommittedDate != nil &&
+		upstreamBranch.Commit != nil && upstreamBranch.Commit.CommittedDate != nil {
+		lag := upstreamBranch.Commit.CommittedDate.Sub(*forkBranch.Commit.CommittedDate)
+		report.DefaultBranchLagSeconds = lag.Seconds()
+		report.DefaultBranchBehind = lag > threshold
+	}
+
+	forkLatestTag, err := latestTagCommitDate(client, fork.ID)
+	if err != nil {
+		return nil, err
+	}
+	upstreamLatestTag, err := latestTagCommitDate(client, upstream.ID)
+	if err != nil {
+		return nil, err
+	}
+	if forkLatestTag != nil && upstreamLatestTag != nil {
+		lag := upstreamLatestTag.Sub(*forkLatestTag)
+		report.LatestTagLagSeconds = lag.Seconds()
+		report.LatestTagBehind = lag > threshold
+	} else if forkLatestTag == nil && upstreamLatestTag != nil {
+		// 上游已有标签而 fork 从未打过标签，视为无限落后
+		report.LatestTagBehind = true
+	}
+
+	return report, nil
+}
+
+// auditDivergenceCmd 定义了 'audit-divergence' 子命令
+var auditDivergenceCmd = &cobra.Command{
+	Use:   "audit-divergence",
+	Short: "检查组内所有 fork 相对上游的落后情况，输出 JSON 供告警流水线消费",
+	Long: `此命令遍历 --group 下所有带有 fork 关系的项目，比较其默认分支的最新提交时间与最新标签时间
+相对上游项目的落后量，超过 --threshold 时标记为落后。结果以 JSON 数组输出到标准输出，
+便于接入告警流水线，而不需要人工逐个项目比对。`,
+	Example: `  gitlab-fork-cli audit-divergence --group fy-prod --threshold 168h`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if auditGroup == "" {
+			logFatal("❌ 错误: 缺少必要的命令行参数 (--group)。")
+		}
+
+		ctx := cmd.Context()
+		kubeRestConfig, err := k8sutil.GetKubeConfig()
+		if err != nil {
+			logFatalf("❌ 无法获取 Kubernetes 配置: %v\n", err)
+		}
+		token, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, auditGroup, GitlabSecretName, GitlabTokenKey)
+		if err != nil {
+			logFatalf("❌ 无法获取组 '%s' 的令牌: %v\n", auditGroup, err)
+		}
+		client, err := newGitLabClient(token, baseURL, insecureSkip, caCertFile)
+		if err != nil {
+			logFatalf("❌ %v\n", err)
+		}
+
+		listOptions := &gitlab.ListGroupProjectsOptions{}
+		listOptions.PerPage = 100
+		listOptions.IncludeSubGroups = gitlab.Ptr(true)
+
+		var reports []*divergenceReport
+		for {
+			projects, resp, err := client.Groups.ListGroupProjects(auditGroup, listOptions)
+			if err != nil {
+				logFatalf("❌ 列出组 '%s' 的项目失败: %v\n", auditGroup, err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				logFatalf("❌ 列出组 '%s' 的项目失败，HTTP 状态码: %d\n", auditGroup, resp.StatusCode)
+			}
+
+			for _, p := range projects {
+				if p.ForkedFromProject == nil {
+					continue
+				}
+				report, err := checkForkDivergence(client, p, auditThreshold)
+				if err != nil {
+					log.Printf("⚠️ 检查项目 '%s' 落后情况失败: %v\n", p.PathWithNamespace, err)
+					reports = append(reports, &divergenceReport{
+						Project:    p.PathWithNamespace,
+						ProjectID:  p.ID,
+						Upstream:   p.ForkedFromProject.PathWithNamespace,
+						UpstreamID: p.ForkedFromProject.ID,
+						Error:      err.Error(),
+					})
+					continue
+				}
+				reports = append(reports, report)
+			}
+
+			if resp.NextPage == 0 {
+				break
+			}
+			listOptions.Page = resp.NextPage
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(reports); err != nil {
+			logFatalf("❌ 序列化落后情况报告失败: %v\n", err)
+		}
+
+		behindCount := 0
+		for _, r := range reports {
+			if r.DefaultBranchBehind || r.LatestTagBehind {
+				behindCount++
+			}
+		}
+		log.Printf("✅ 检查完成，共 %d 个 fork，其中 %d 个落后超过阈值 (%s)。\n", len(reports), behindCount, auditThreshold)
+	},
+}
+
+func init() {
+	auditDivergenceCmd.Flags().StringVarP(&auditGroup, "group", "g", "", "待检查的 NS 名称 (必填)")
+	auditDivergenceCmd.Flags().DurationVar(&auditThreshold, "threshold", 72*time.Hour, "落后超过该时长的默认分支/标签会被标记为落后")
+
+	categorizeFlag(auditDivergenceCmd, "threshold", "behavior")
+
+	auditDivergenceCmd.MarkFlagRequired("group")
+
+	rootCmd.AddCommand(auditDivergenceCmd)
+}
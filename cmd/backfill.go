@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg"
+	"github.com/spf13/cobra"
+)
+
+// 定义 backfill 命令的参数变量，与 'mirror'/'diff-refs' 保持一致的 --from-*/--to-* 寻址约定
+var (
+	backfillFromRepoURL   string
+	backfillFromProject   string
+	backfillFromToken     string
+	backfillFromTokenFile string
+	backfillToRepoURL     string
+	backfillToProject     string
+	backfillToToken       string
+	backfillToTokenFile   string
+	backfillPromptToken   bool
+	backfillTagFilter     string
+	backfillSince         string
+	backfillInterval      time.Duration
+	backfillOutputDir     string
+)
+
+// backfillTagResult 记录批量补齐中单个标签的处理结果
+type backfillTagResult struct {
+	Tag string
+	Err error
+}
+
+// parseBackfillSince 解析 --since，兼容 RFC3339 (与 clone 命令 --min-commit-date 一致) 与
+// 更便于命令行输入的 "YYYY-MM-DD" 日期格式
+func parseBackfillSince(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("无法解析 '%s'，需为 RFC3339 (如 2024-01-02T15:04:05Z) 或 YYYY-MM-DD 格式", value)
+}
+
+// backfillCmd 定义了 'backfill' 子命令
+var backfillCmd = &cobra.Command{
+	Use:   "backfill",
+	Short: "将源仓库上目标缺失的历史标签逐个补齐到目标仓库",
+	Long: `此命令比较源/目标仓库的标签集合 (复用 'diff-refs' 的 pkg.DiffRemoteRefs)，
+找出仅存在于源仓库的标签，按 --tag-filter 过滤后逐个克隆并推送到目标仓库，
+用于将一个已有的模型仓库接入镜像体系时补齐历史发布记录，而不必逐个手动运行 'clone'。
+标签按名称的字典序依次处理 (为避免逐个标签额外克隆一次以获取提交时间，不做严格的按提交时间排序，
+字典序对语义化版本号/日期前缀命名的标签通常已经等价于按时间顺序)；
+--since 会对每个标签校验其提交时间不早于该时间 (复用 'clone --min-commit-date' 的同一机制)，
+不满足的标签记为失败并跳过，不影响其余标签的处理；--interval 可在相邻两个标签之间插入等待，
+避免短时间内对目标 GitLab 实例造成推送压力。`,
+	Example: `  gitlab-fork-cli backfill --from-repo-url https://gitlab.example.com/dev/app.git --to-repo-url https://gitlab.example.com/prod/app.git --tag-filter 'v*'
+  gitlab-fork-cli backfill --from-project dev/app --to-project prod/app --tag-filter 'v*' --since 2024-01-01 --interval 5s`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if backfillFromRepoURL != "" && backfillFromProject != "" {
+			logFatal("❌ 错误: --from-repo-url 与 --from-project 只能提供一个。")
+		}
+		if backfillFromRepoURL == "" && backfillFromProject == "" {
+			logFatal("❌ 错误: 必须提供 --from-repo-url 或 --from-project 中的一个。")
+		}
+		if backfillFromProject != "" {
+			backfillFromRepoURL = buildRepoURLFromProject(baseURL, backfillFromProject)
+		}
+		if backfillToRepoURL != "" && backfillToProject != "" {
+			logFatal("❌ 错误: --to-repo-url 与 --to-project 只能提供一个。")
+		}
+		if backfillToRepoURL == "" && backfillToProject == "" {
+			logFatal("❌ 错误: 必须提供 --to-repo-url 或 --to-project 中的一个。")
+		}
+		if backfillToProject != "" {
+			backfillToRepoURL = buildRepoURLFromProject(baseURL, backfillToProject)
+		}
+		if backfillTagFilter == "" {
+			backfillTagFilter = "*"
+		}
+
+		var since *time.Time
+		if backfillSince != "" {
+			parsed, err := parseBackfillSince(backfillSince)
+			if err != nil {
+				logFatalf("❌ 无法解析 --since: %v\n", err)
+			}
+			since = &parsed
+		}
+
+		resolvedFromToken, err := resolveCloneToken(backfillFromToken, backfillFromTokenFile, gitlabFromTokenEnvVar, "源仓库令牌 (--from-token)", backfillPromptToken)
+		if err != nil {
+			logFatalf("❌ %v\n", err)
+		}
+		var fromAuth pkg.GitAuthMethod
+		if resolvedFromToken != "" {
+			fromAuth = &pkg.BasicAuthMethod{Username: "oauth2", Password: resolvedFromToken}
+		}
+
+		resolvedToToken, err := resolveCloneToken(backfillToToken, backfillToTokenFile, gitlabToTokenEnvVar, "目的仓库令牌 (--to-token)", backfillPromptToken)
+		if err != nil {
+			logFatalf("❌ %v\n", err)
+		}
+		var toAuth pkg.GitAuthMethod
+		if resolvedToToken != "" {
+			toAuth = &pkg.BasicAuthMethod{Username: "oauth2", Password: resolvedToToken}
+		}
+
+		ctx := cmd.Context()
+		var caBundle []byte
+		if caCertFile != "" {
+			caBundle, err = os.ReadFile(caCertFile)
+			if err != nil {
+				logFatalf("❌ 读取 CA 证书文件 '%s' 失败: %v\n", caCertFile, err)
+			}
+		}
+
+		diff, err := pkg.DiffRemoteRefs(ctx, backfillFromRepoURL, fromAuth, backfillToRepoURL, toAuth, insecureSkip, caBundle)
+		if err != nil {
+			logFatalf("❌ 比较标签集合失败: %v\n", err)
+		}
+
+		var tags []string
+		for _, tag := range diff.SourceOnlyTags {
+			matched, err := path.Match(backfillTagFilter, tag)
+			if err != nil {
+				logFatalf("❌ --tag-filter '%s' 不是合法的 glob 表达式: %v\n", backfillTagFilter, err)
+			}
+			if matched {
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) == 0 {
+			log.Println("✅ 源仓库没有目标仓库缺失且匹配 --tag-filter 的标签，无需补齐。")
+			return
+		}
+
+		if readOnlyGuard(fmt.Sprintf("将 '%s' 上 %d 个目标缺失的标签补齐到 '%s'", backfillFromRepoURL, len(tags), backfillToRepoURL)) {
+			return
+		}
+
+		outputDir := backfillOutputDir
+		if outputDir == "" {
+			source := rand.NewSource(time.Now().UnixNano())
+			r := rand.New(source)
+			outputDir = filepath.Join(os.TempDir(), "gitlab-fork-cli-backfill-"+strconv.Itoa(r.Intn(100000)))
+		}
+
+		log.Printf("🚀 正在将 %d 个标签从 '%s' 补齐到 '%s' (tag-filter=%s)...\n", len(tags), backfillFromRepoURL, backfillToRepoURL, backfillTagFilter)
+
+		results := make([]backfillTagResult, 0, len(tags))
+		for i, tag := range tags {
+			if i > 0 && backfillInterval > 0 {
+				time.Sleep(backfillInterval)
+			}
+
+			tagOutputDir := filepath.Join(outputDir, tag)
+			err := pkg.PerformGitOperation(ctx, pkg.GitOperationOptions{
+				FromRepoURL:     backfillFromRepoURL,
+				FromRef:         tag,
+				FromAuth:        fromAuth,
+				ToRepoURL:       backfillToRepoURL,
+				ToTag:           tag,
+				ToAuth:          toAuth,
+				OutputDir:       tagOutputDir,
+				ProgressWriter:  os.Stdout,
+				MinCommitDate:   since,
+				CleanupRemote:   true,
+				InsecureSkipTLS: insecureSkip,
+				CACertFile:      caCertFile,
+			})
+			results = append(results, backfillTagResult{Tag: tag, Err: err})
+			if err != nil {
+				log.Printf("  ❌ %s: %v\n", tag, err)
+			} else {
+				log.Printf("  ✅ %s\n", tag)
+			}
+		}
+
+		succeeded, failed := 0, 0
+		for _, r := range results {
+			if r.Err == nil {
+				succeeded++
+			} else {
+				failed++
+			}
+		}
+		log.Printf("\n🎉 补齐完成，共处理 %d 个标签，成功 %d 个，失败/跳过 %d 个。\n", len(results), succeeded, failed)
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	backfillCmd.Flags().StringVar(&backfillFromRepoURL, "from-repo-url", "", "源 Git 仓库的完整 URL (与 --from-project 二选一，必填其一)")
+	backfillCmd.Flags().StringVar(&backfillFromProject, "from-project", "", "源项目路径 (如 'group/project')，由工具拼接 --base-url 得到完整 URL (与 --from-repo-url 二选一)")
+	backfillCmd.Flags().StringVar(&backfillFromToken, "from-token", "", "源仓库用于认证的个人访问令牌 (可选，未提供时依次尝试 --from-token-file、"+gitlabFromTokenEnvVar+" 环境变量、--prompt-token)")
+	backfillCmd.Flags().StringVar(&backfillFromTokenFile, "from-token-file", "", "从文件读取源仓库令牌 (可选)")
+	backfillCmd.Flags().StringVar(&backfillToRepoURL, "to-repo-url", "", "目的 Git 仓库的完整 URL (与 --to-project 二选一，必填其一)")
+	backfillCmd.Flags().StringVar(&backfillToProject, "to-project", "", "目的项目路径 (如 'group/project')，由工具拼接 --base-url 得到完整 URL (与 --to-repo-url 二选一)")
+	backfillCmd.Flags().StringVar(&backfillToToken, "to-token", "", "目的仓库用于认证的个人访问令牌 (可选)")
+	backfillCmd.Flags().StringVar(&backfillToTokenFile, "to-token-file", "", "从文件读取目的仓库令牌 (可选)")
+	backfillCmd.Flags().BoolVar(&backfillPromptToken, "prompt-token", false, "任一令牌未通过标志/文件/环境变量提供时，交互式从终端读取 (不回显)")
+	backfillCmd.Flags().StringVar(&backfillTagFilter, "tag-filter", "*", "筛选待补齐标签的 glob 表达式 (如 'v*')，默认匹配全部标签")
+	backfillCmd.Flags().StringVar(&backfillSince, "since", "", "只补齐提交时间不早于该时间的标签 (RFC3339 或 YYYY-MM-DD)，为空表示不限制")
+	backfillCmd.Flags().DurationVar(&backfillInterval, "interval", 0, "相邻两个标签之间的等待时间，用于限制对目标 GitLab 实例的推送速率 (如 5s)")
+	backfillCmd.Flags().StringVar(&backfillOutputDir, "output-dir", "", "各标签克隆到的本地目录的父目录 (可选，默认为临时目录，每个标签使用其下的独立子目录)")
+
+	for _, name := range []string{"from-token", "to-token", "from-token-file", "to-token-file", "prompt-token"} {
+		categorizeFlag(backfillCmd, name, "auth")
+	}
+	for _, name := range []string{"from-project", "to-project", "tag-filter", "since", "interval"} {
+		categorizeFlag(backfillCmd, name, "behavior")
+	}
+	categorizeFlag(backfillCmd, "output-dir", "output")
+
+	rootCmd.AddCommand(backfillCmd)
+}
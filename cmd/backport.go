@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg"
+	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
+	"github.com/spf13/cobra"
+)
+
+// 定义 backport 命令的参数变量
+var (
+	backportFromGroup   string   // 生产 fork 所在的组 (紧急修复的来源)
+	backportToGroup     string   // 上游开发组 (紧急修复要回流到的目标)
+	backportProject     string   // 项目名称，需在来源组与目标组下同名存在
+	backportTag         string   // 待回流的生产 hotfix 标签
+	backportReason      string   // 回流原因，用于审计留痕 (策略检查之一，禁止空原因的静默回流)
+	backportOnTagExists string   // 处理目标仓库标签已存在的行为
+	backportAlsoTags    []string // --also-tag 指定的移动标签 (如 stable、canary)，随主标签一并强制指向本次提交
+)
+
+// backportCmd 定义了 'backport' 子命令，用于将生产 fork 上的紧急修复标签回流到上游开发项目。
+var backportCmd = &cobra.Command{
+	Use:   "backport",
+	Short: "将生产 fork 上的 hotfix 标签回流到上游开发项目",
+	Long: `紧急情况下，修复有时会直接打在生产 fork 上而不是上游开发项目，事后必须回流，
+否则下一次派生会静默丢弃这个修复。此命令将 --from-group (生产) 下指定项目的 --tag
+推回到 --to-group (开发) 下的同名项目，方向与 fork 命令相反。
+
+回流是一次不受常规派生流程保护的写操作，因此要求显式填写 --reason 留痕，
+且默认在目标仓库已存在同名标签时报错退出，避免误覆盖上游历史。`,
+	Example: `  gitlab-fork-cli backport --from-group fy-prod --to-group fy-dev --project iris --tag v1.2.3-hotfix1 --reason "紧急修复生产内存泄漏"
+  gitlab-fork-cli backport --from-group fy-prod --to-group fy-dev --project iris --tag v1.2.3-hotfix1 --reason "..." --on-tag-exists skip`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 1. 校验必填参数，回流原因不允许为空 (策略检查：禁止无留痕的静默回流)
+		if backportFromGroup == "" || backportToGroup == "" || backportProject == "" || backportTag == "" || baseURL == "" {
+			logFatal("❌ 错误: 缺少必要的命令行参数。请使用 --help 查看用法。")
+		}
+		if backportReason == "" {
+			logFatal("❌ 错误: 必须通过 --reason 说明本次回流的原因，用于审计留痕。")
+		}
+
+		warnings := pkg.NewWarningCollector()
+		warnings.SetStrict(strictMode)
+		warnings.SetCorrelationID(correlationID)
+		defer warnings.PrintSummary()
+		if insecureSkip {
+			if err := warnings.Add("insecure-tls", "已通过 --insecure 关闭 TLS 证书校验"); err != nil {
+				logFatalf("❌ %v", err)
+			}
+		}
+
+		timeline := pkg.NewTimeline()
+		if verbose {
+			defer timeline.PrintSummary()
+		}
+		timeline.StartPhase("resolve")
+
+		log.Println("ℹ️ 正在获取 Kubernetes 配置...")
+		ctx := cmd.Context()
+		kubeRestConfig, err := k8sutil.GetKubeConfig()
+		if err != nil {
+			logFatalf("❌ 无法获取 Kubernetes 配置，无法检查命名空间或获取 Secret。错误: %v\n", err)
+		}
+
+		// 2. 检查来源组 (生产) 与目标组 (开发) 对应的命名空间是否存在
+		for _, ns := range []string{backportFromGroup, backportToGroup} {
+			exists, err := k8sutil.CheckK8sNamespaceExists(ctx, kubeRestConfig, ns)
+			if err != nil {
+				logFatalf("❌ 检查命名空间 '%s' 失败: %v\n", ns, err)
+			}
+			if !exists {
+				logFatalf("❌ 命名空间 '%s' 不存在。请确认该命名空间已被纳管。\n", ns)
+			}
+		}
+
+		// 3. 分别获取来源组、目标组的令牌
+		fromToken, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, backportFromGroup, GitlabSecretName, GitlabTokenKey)
+		if err != nil {
+			logFatalf("❌ 无法获取来源组 '%s' 的令牌: %v\n", backportFromGroup, err)
+		}
+		toToken, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, backportToGroup, GitlabSecretName, GitlabTokenKey)
+		if err != nil {
+			logFatalf("❌ 无法获取目标组 '%s' 的令牌: %v\n", backportToGroup, err)
+		}
+
+		fromRepoURL := fmt.Sprintf("%s/%s/%s.git", baseURL, getModelGroupByNs(backportFromGroup), backportProject)
+		toRepoURL := fmt.Sprintf("%s/%s/%s.git", baseURL, getModelGroupByNs(backportToGroup), backportProject)
+
+		outputDir, err := os.MkdirTemp("", "gitlab-fork-cli-backport-")
+		if err != nil {
+			logFatalf("❌ 创建临时目录失败: %v\n", err)
+		}
+		defer os.RemoveAll(outputDir)
+
+		if readOnlyGuard(fmt.Sprintf("将 '%s' 的标签 '%s' 回流到 '%s'", fromRepoURL, backportTag, toRepoURL)) {
+			return
+		}
+
+		log.Printf("🚀 正在将 '%s' 的标签 '%s' 回流到 '%s' (原因: %s)...\n", fromRepoURL, backportTag, toRepoURL, backportReason)
+
+		timeline.StartPhase("push")
+		opts := pkg.GitOperationOptions{
+			FromRepoURL:         fromRepoURL,
+			FromRef:             backportTag,
+			FromAuth:            &pkg.BasicAuthMethod{Username: "oauth2", Password: fromToken},
+			ToRepoURL:           toRepoURL,
+			ToTag:               backportTag,
+			ToAuth:              &pkg.BasicAuthMethod{Username: "oauth2", Password: toToken},
+			OutputDir:           outputDir,
+			ProgressWriter:      os.Stdout,
+			OnTagExistsBehavior: backportOnTagExists,
+			MovingTags:          backportAlsoTags,
+			Warnings:            warnings,
+			Timeline:            timeline,
+			InsecureSkipTLS:     insecureSkip,
+			CACertFile:          caCertFile,
+		}
+		if err := pkg.PerformGitOperation(ctx, opts); err != nil {
+			logFatalf("❌ 回流失败: %v\n", err)
+		}
+
+		log.Println("\n✅ 回流完成，hotfix 标签已推送到上游开发项目。")
+
+		// 4. 记录本次成功回流为目标项目的最后已知良好 (last-known-good) 标签，
+		// 供 'last-good' 命令查询，帮助事故响应人员在下次紧急情况下快速定位回滚目标。
+		if statePath, err := pkg.DefaultPromotionStorePath(); err != nil {
+			log.Printf("⚠️ 无法确定晋级状态文件路径，跳过记录最后已知良好标签: %v\n", err)
+		} else if err := pkg.NewPromotionStore(statePath).RecordSuccess(backportToGroup, backportProject, backportTag, backportReason, time.Now()); err != nil {
+			log.Printf("⚠️ 记录最后已知良好标签失败 (不影响本次回流结果): %v\n", err)
+		}
+	},
+}
+
+func init() {
+	backportCmd.Flags().StringVar(&backportFromGroup, "from-group", "", "生产 fork 所在的 NS 名称，即 hotfix 的来源 (必填)")
+	backportCmd.Flags().StringVar(&backportToGroup, "to-group", "", "上游开发组的 NS 名称，即 hotfix 要回流到的目标 (必填)")
+	backportCmd.Flags().StringVar(&backportProject, "project", "", "项目名称，需在来源组与目标组下同名存在 (必填)")
+	backportCmd.Flags().StringVar(&backportTag, "tag", "", "待回流的生产 hotfix 标签 (必填)")
+	backportCmd.Flags().StringVar(&backportReason, "reason", "", "本次回流的原因，用于审计留痕 (必填)")
+	backportCmd.Flags().StringVar(&backportOnTagExists, "on-tag-exists", "error", "处理目标仓库标签已存在的行为：'error' (默认，报错)、'skip' (跳过) 或 'overwrite' (指向不同提交时强制覆盖)")
+	backportCmd.Flags().StringSliceVar(&backportAlsoTags, "also-tag", nil, "随主标签一并强制指向本次提交的移动标签，可重复指定或用逗号分隔 (如 stable、canary)，供 serving controller 监听")
+
+	categorizeFlag(backportCmd, "reason", "behavior")
+	categorizeFlag(backportCmd, "on-tag-exists", "behavior")
+	categorizeFlag(backportCmd, "also-tag", "behavior")
+
+	backportCmd.MarkFlagRequired("from-group")
+	backportCmd.MarkFlagRequired("to-group")
+	backportCmd.MarkFlagRequired("project")
+	backportCmd.MarkFlagRequired("tag")
+	backportCmd.MarkFlagRequired("reason")
+
+	rootCmd.AddCommand(backportCmd)
+}
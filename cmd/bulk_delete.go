@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg"
+	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
+	"github.com/spf13/cobra"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"gopkg.in/yaml.v3"
+	"k8s.io/client-go/rest"
+)
+
+// 定义 bulk-delete 命令的参数变量
+var (
+	bulkDeleteManifestPath string
+	bulkDeleteConfirm      bool
+	bulkDeleteDelay        time.Duration
+)
+
+// bulkDeleteEntry 描述 --manifest 文件中的一条待删除条目：Tag 非空时只删除该标签，
+// 为空时删除整个项目
+type bulkDeleteEntry struct {
+	Group   string `yaml:"group"`
+	Project string `yaml:"project"`
+	Tag     string `yaml:"tag,omitempty"`
+}
+
+// loadBulkDeleteManifest 读取并解析 --manifest 指定的来源 (本地文件路径，或 "-"/"http(s)://"/"configmap://"
+// 等 readSource 支持的其他来源) 中的 YAML 内容
+func loadBulkDeleteManifest(ctx context.Context, source string) ([]bulkDeleteEntry, error) {
+	data, err := readSource(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("读取 manifest '%s' 失败: %w", source, err)
+	}
+
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("解析 manifest '%s' 失败: %w", source, err)
+	}
+	if err := pkg.ValidateAgainstSchema("bulk-delete-manifest", raw); err != nil {
+		return nil, fmt.Errorf("manifest '%s' 不符合 bulk-delete-manifest schema: %w", source, err)
+	}
+
+	var entries []bulkDeleteEntry
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("解析 manifest '%s' 失败: %w", source, err)
+	}
+	for i, e := range entries {
+		if e.Group == "" || e.Project == "" {
+			return nil, fmt.Errorf("manifest 第 %d 条条目缺少 group/project 中的一项", i+1)
+		}
+	}
+	return entries, nil
+}
+
+// bulkDeleteCmd 定义了 'bulk-delete' 子命令
+var bulkDeleteCmd = &cobra.Command{
+	Use:   "bulk-delete",
+	Short: "按 --manifest 中经过评审的清单批量删除项目或标签，用于定期清理实验性 fork",
+	Long: `此命令读取 --manifest 指定的 YAML 文件 (每条为 {group, project} 删除整个项目，
+或 {group, project, tag} 只删除其中一个标签)，用于定期清理批量实验/A-B 派生遗留下的项目。
+
+删除是不可逆操作，因此默认永远只做 dry-run：打印将要删除的完整清单供人工复核，
+不实际调用任何删除接口；只有显式提供 --confirm 才会真正执行删除。执行时按 --delay
+指定的间隔逐条限速删除，避免短时间内大量删除请求触发 GitLab 的速率限制。`,
+	Example: `  gitlab-fork-cli bulk-delete --manifest doomed.yaml
+  gitlab-fork-cli bulk-delete --manifest doomed.yaml --confirm --delay 2s`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if bulkDeleteManifestPath == "" {
+			logFatal("❌ 错误: 必须提供 --manifest 参数。")
+		}
+
+		ctx := cmd.Context()
+		entries, err := loadBulkDeleteManifest(ctx, bulkDeleteManifestPath)
+		if err != nil {
+			logFatalf("❌ %v\n", err)
+		}
+		if len(entries) == 0 {
+			logFatal("❌ manifest 文件中没有任何条目。")
+		}
+
+		projectCount, tagCount := 0, 0
+		for _, e := range entries {
+			if e.Tag == "" {
+				projectCount++
+			} else {
+				tagCount++
+			}
+		}
+
+		log.Printf("📋 manifest '%s' 共 %d 条删除条目 (整体删除项目 %d 个，删除标签 %d 个):\n",
+			bulkDeleteManifestPath, len(entries), projectCount, tagCount)
+		for i, e := range entries {
+			if e.Tag == "" {
+				log.Printf("  %d. 删除项目 '%s/%s'\n", i+1, e.Group, e.Project)
+			} else {
+				log.Printf("  %d. 删除标签 '%s/%s:%s'\n", i+1, e.Group, e.Project, e.Tag)
+			}
+		}
+
+		if !bulkDeleteConfirm {
+			log.Println("🔒 未指定 --confirm，以上为 dry-run 预览，未执行任何删除。复核无误后追加 --confirm 重新运行以真正删除。")
+			return
+		}
+
+		warnings := pkg.NewWarningCollector()
+		warnings.SetStrict(strictMode)
+		warnings.SetCorrelationID(correlationID)
+		defer warnings.PrintSummary()
+
+		kubeRestConfig, err := k8sutil.GetKubeConfig()
+		if err != nil {
+			logFatalf("❌ 无法获取 Kubernetes 配置。错误: %v\n", err)
+		}
+
+		// 按组缓存已创建的 GitLab 客户端，避免同一个组下的多条条目重复获取令牌
+		clients := map[string]*gitlab.Client{}
+		clientFor := func(group string) (*gitlab.Client, error) {
+			if c, ok := clients[group]; ok {
+				return c, nil
+			}
+			c, err := newGitLabClientForGroup(ctx, kubeRestConfig, group)
+			if err != nil {
+				return nil, err
+			}
+			clients[group] = c
+			return c, nil
+		}
+
+		deleted, failed := 0, 0
+		for i, e := range entries {
+			if i > 0 && bulkDeleteDelay > 0 {
+				time.Sleep(bulkDeleteDelay)
+			}
+
+			label := fmt.Sprintf("%s/%s", e.Group, e.Project)
+			if e.Tag != "" {
+				label = fmt.Sprintf("%s:%s", label, e.Tag)
+			}
+
+			git, err := clientFor(e.Group)
+			if err != nil {
+				failed++
+				if warnErr := warnings.Add("bulk-delete-client-failed", "为组 '%s' 创建 GitLab 客户端失败: %v", e.Group, err); warnErr != nil {
+					logFatalf("❌ %v", warnErr)
+				}
+				log.Printf("⚠️ %v\n", err)
+				continue
+			}
+
+			groupPath := getModelGroupByNs(e.Group)
+			projectID, err := findProjectInGroup(git, groupPath, e.Project, groupEnumFilter{includeArchived: true})
+			if err != nil {
+				failed++
+				if warnErr := warnings.Add("bulk-delete-project-not-found", "未找到项目 '%s': %v", label, err); warnErr != nil {
+					logFatalf("❌ %v", warnErr)
+				}
+				log.Printf("⚠️ 未找到项目 '%s': %v\n", label, err)
+				continue
+			}
+
+			if e.Tag == "" {
+				if readOnlyGuard(fmt.Sprintf("删除项目 '%s'", label)) {
+					continue
+				}
+				if _, err := git.Projects.DeleteProject(projectID, nil); err != nil {
+					failed++
+					if warnErr := warnings.Add("bulk-delete-failed", "删除项目 '%s' 失败: %v", label, err); warnErr != nil {
+						logFatalf("❌ %v", warnErr)
+					}
+					log.Printf("⚠️ 删除项目 '%s' 失败: %v\n", label, err)
+					continue
+				}
+				log.Printf("🗑️  已删除项目 '%s'。\n", label)
+				deleted++
+			} else {
+				if readOnlyGuard(fmt.Sprintf("删除标签 '%s'", label)) {
+					continue
+				}
+				if _, err := git.Tags.DeleteTag(projectID, e.Tag); err != nil {
+					failed++
+					if warnErr := warnings.Add("bulk-delete-failed", "删除标签 '%s' 失败: %v", label, err); warnErr != nil {
+						logFatalf("❌ %v", warnErr)
+					}
+					log.Printf("⚠️ 删除标签 '%s' 失败: %v\n", label, err)
+					continue
+				}
+				log.Printf("🗑️  已删除标签 '%s'。\n", label)
+				deleted++
+			}
+		}
+
+		log.Printf("🎉 bulk-delete 完成，共 %d 条条目，成功删除 %d 个，失败 %d 个。\n", len(entries), deleted, failed)
+	},
+}
+
+// newGitLabClientForGroup 按 --group 对应的命名空间解析令牌并创建 GitLab 客户端，
+// 抽取自各命令中重复的 "取 Secret -> newGitLabClient" 两步逻辑
+func newGitLabClientForGroup(ctx context.Context, kubeRestConfig *rest.Config, group string) (*gitlab.Client, error) {
+	token, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, group, GitlabSecretName, GitlabTokenKey)
+	if err != nil {
+		return nil, fmt.Errorf("无法获取组 '%s' 的令牌: %w", group, err)
+	}
+	git, err := newGitLabClient(token, baseURL, insecureSkip, caCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("创建 GitLab 客户端失败: %w", err)
+	}
+	return git, nil
+}
+
+func init() {
+	bulkDeleteCmd.Flags().StringVar(&bulkDeleteManifestPath, "manifest", "", "待删除条目清单的 YAML 来源 (必填)。除本地文件路径外还支持 '-' (标准输入)、'http(s)://' URL、'configmap://<namespace>/<name>/<key>'，便于 GitOps 系统直接传入生成的 manifest")
+	bulkDeleteCmd.Flags().BoolVar(&bulkDeleteConfirm, "confirm", false, "真正执行删除；未提供时始终只打印 dry-run 预览 (⚠️ 删除不可逆，务必先不带此标志复核清单)")
+	bulkDeleteCmd.Flags().DurationVar(&bulkDeleteDelay, "delay", time.Second, "相邻两次删除之间的等待间隔，用于限速，避免触发 GitLab 速率限制")
+
+	categorizeFlag(bulkDeleteCmd, "confirm", "behavior")
+	categorizeFlag(bulkDeleteCmd, "delay", "behavior")
+
+	bulkDeleteCmd.MarkFlagRequired("manifest")
+
+	rootCmd.AddCommand(bulkDeleteCmd)
+}
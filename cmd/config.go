@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg"
+	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFileName 是 --config 未显式指定时，在用户主目录下查找的默认配置文件名
+const defaultConfigFileName = ".gitlab-fork-cli.yaml"
+
+// globalConfigFilePath 对应全局 --config 标志
+var globalConfigFilePath string
+
+// globalDefaultsConfig 描述 ~/.gitlab-fork-cli.yaml (或 --config 指定的文件) 中可覆盖的全局默认值：
+// GitLab 基础 URL、令牌 Secret 名称/key、amlmodels 子组名称，均原为硬编码常量。
+// 与 ConfigProfile (用于 'config validate' 校验一整套环境接入配置) 是两个不同的概念：
+// 本结构体只覆盖进程级默认值，不声明任何组。
+type globalDefaultsConfig struct {
+	BaseURL     string `yaml:"baseUrl"`
+	SecretName  string `yaml:"secretName"`
+	SecretKey   string `yaml:"secretKey"`
+	ModelsGroup string `yaml:"modelsGroup"`
+}
+
+// loadGlobalConfigDefaults 加载 --config 指定 (或默认位于 ~/.gitlab-fork-cli.yaml) 的配置文件，
+// 覆盖 baseURL/GitlabSecretName/GitlabTokenKey/amlModelsGroup 的默认值；--base-url 已被显式传参时
+// 不覆盖，命令行参数始终优先于配置文件。除本地文件路径外，--config 显式指定时还支持 readSource
+// 支持的其他来源 ("-"/"http(s)://"/"configmap://...")；未显式指定时只查找默认本地路径，不适用这些来源。
+// 默认路径不存在时静默跳过，不视为错误；通过 --config 显式指定的来源若读取或解析失败则报错退出。
+func loadGlobalConfigDefaults(cmd *cobra.Command) {
+	path := globalConfigFilePath
+	explicit := path != ""
+	if !explicit {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return
+		}
+		path = filepath.Join(home, defaultConfigFileName)
+		if _, err := os.Stat(path); err != nil {
+			return
+		}
+	}
+
+	data, err := readSource(cmd.Context(), path)
+	if err != nil {
+		if explicit {
+			logFatalf("❌ 读取配置文件 '%s' 失败: %v\n", path, err)
+		}
+		return
+	}
+
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		logFatalf("❌ 解析配置文件 '%s' 失败: %v\n", path, err)
+	}
+	if err := pkg.ValidateAgainstSchema("global-config", raw); err != nil {
+		logFatalf("❌ 配置文件 '%s' 不符合 global-config schema: %v\n", path, err)
+	}
+
+	var cfg globalDefaultsConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		logFatalf("❌ 解析配置文件 '%s' 失败: %v\n", path, err)
+	}
+
+	if cfg.BaseURL != "" && !cmd.Flags().Changed("base-url") {
+		baseURL = cfg.BaseURL
+	}
+	if cfg.SecretName != "" {
+		GitlabSecretName = cfg.SecretName
+	}
+	if cfg.SecretKey != "" {
+		GitlabTokenKey = cfg.SecretKey
+	}
+	if cfg.ModelsGroup != "" {
+		amlModelsGroup = cfg.ModelsGroup
+	}
+	log.Printf("ℹ️ 已从配置文件 '%s' 加载默认值。\n", path)
+}
+
+// ConfigProfile 描述一份环境配置文件：一个 GitLab 实例地址，以及若干个组，
+// 每个组对应一个 k8s 命名空间及其中保存 GitLab 令牌的 Secret。
+// 这是后续 `--profile` 相关命令共用的最小配置格式，本命令只做只读校验，不做任何变更。
+type ConfigProfile struct {
+	BaseURL string               `yaml:"baseUrl"`
+	Groups  []ConfigProfileGroup `yaml:"groups"`
+}
+
+// ConfigProfileGroup 描述一个组在配置文件中的引用
+type ConfigProfileGroup struct {
+	Name       string `yaml:"name"`
+	SecretName string `yaml:"secretName"`
+	SecretKey  string `yaml:"secretKey"`
+}
+
+// 定义 config 命令的参数变量
+var configProfilePath string
+
+// configCmd 定义了 'config' 命令，作为配置相关子命令的父命令
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "配置文件相关操作",
+}
+
+// configValidateCmd 定义了 'config validate' 子命令
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "加载配置/profile 并校验其中引用的 k8s Secret 与 GitLab 端点，不做任何变更",
+	Long: `此命令加载指定的配置/profile 文件，逐一解析其中引用的 k8s Secret 与 GitLab 端点，
+并一次性汇总所有问题，而不是发现第一个问题就退出，便于新环境接入时一次性核对完毕。
+整个过程只读，不会创建或修改任何资源。`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if configProfilePath == "" {
+			logFatal("❌ 错误: 必须提供 --profile 参数。")
+		}
+
+		ctx := cmd.Context()
+		data, err := readSource(ctx, configProfilePath)
+		if err != nil {
+			logFatalf("❌ 无法读取配置文件 '%s': %v\n", configProfilePath, err)
+		}
+
+		var raw interface{}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			logFatalf("❌ 解析配置文件 '%s' 失败: %v\n", configProfilePath, err)
+		}
+
+		var profile ConfigProfile
+		if err := yaml.Unmarshal(data, &profile); err != nil {
+			logFatalf("❌ 解析配置文件 '%s' 失败: %v\n", configProfilePath, err)
+		}
+
+		var problems []string
+
+		if err := pkg.ValidateAgainstSchema("config-profile", raw); err != nil {
+			problems = append(problems, fmt.Sprintf("不符合 config-profile schema: %v", err))
+		}
+
+		if profile.BaseURL == "" {
+			problems = append(problems, "顶层字段 'baseUrl' 未设置")
+		}
+		if len(profile.Groups) == 0 {
+			problems = append(problems, "顶层字段 'groups' 为空，未声明任何组")
+		}
+
+		kubeRestConfig, err := k8sutil.GetKubeConfig()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("无法获取 Kubernetes 配置，后续所有组的 Secret 校验均无法进行: %v", err))
+		}
+
+		for _, g := range profile.Groups {
+			if g.Name == "" {
+				problems = append(problems, "存在一个未设置 'name' 的组条目")
+				continue
+			}
+			secretName := g.SecretName
+			if secretName == "" {
+				secretName = GitlabSecretName
+			}
+			secretKey := g.SecretKey
+			if secretKey == "" {
+				secretKey = GitlabTokenKey
+			}
+
+			if kubeRestConfig == nil {
+				continue
+			}
+
+			nsExists, err := k8sutil.CheckK8sNamespaceExists(ctx, kubeRestConfig, g.Name)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("组 '%s': 检查命名空间失败: %v", g.Name, err))
+				continue
+			}
+			if !nsExists {
+				problems = append(problems, fmt.Sprintf("组 '%s': 对应的 Kubernetes 命名空间不存在", g.Name))
+				continue
+			}
+
+			token, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, g.Name, secretName, secretKey)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("组 '%s': 无法获取 Secret '%s' 的 key '%s': %v", g.Name, secretName, secretKey, err))
+				continue
+			}
+
+			if profile.BaseURL != "" {
+				client, err := newGitLabClient(token, profile.BaseURL, insecureSkip, caCertFile)
+				if err != nil {
+					problems = append(problems, fmt.Sprintf("组 '%s': 创建 GitLab 客户端失败: %v", g.Name, err))
+					continue
+				}
+				if _, _, err := client.Version.GetVersion(); err != nil {
+					problems = append(problems, fmt.Sprintf("组 '%s': 使用其令牌访问 GitLab 实例 '%s' 失败: %v", g.Name, profile.BaseURL, err))
+				}
+			}
+		}
+
+		if len(problems) == 0 {
+			log.Println("✅ 配置文件校验通过，未发现问题。")
+			return
+		}
+
+		log.Printf("❌ 配置文件校验发现 %d 个问题:\n", len(problems))
+		for i, p := range problems {
+			log.Printf("  %d. %s\n", i+1, p)
+		}
+		os.Exit(1)
+	},
+}
+
+func init() {
+	configValidateCmd.Flags().StringVar(&configProfilePath, "profile", "", "配置/profile 来源 (YAML，必填)。除本地文件路径外还支持 '-' (标准输入)、'http(s)://' URL、'configmap://<namespace>/<name>/<key>'")
+	configValidateCmd.MarkFlagRequired("profile")
+
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
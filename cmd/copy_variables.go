@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg"
+	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
+	"github.com/spf13/cobra"
+)
+
+// 定义 copy-variables 命令的参数变量
+var (
+	copyVariablesFromGroup     string
+	copyVariablesFromProject   string
+	copyVariablesToGroup       string
+	copyVariablesToProject     string
+	copyVariablesSkipProtected bool
+	copyVariablesSkipMasked    bool
+)
+
+// copyVariablesCmd 定义了 'copy-variables' 子命令，独立于 fork 命令使用，将一个已存在项目的
+// CI/CD 变量复制到另一个已存在项目，用于派生完成之后再补齐变量、或在两个已有项目之间同步变量。
+var copyVariablesCmd = &cobra.Command{
+	Use:   "copy-variables",
+	Short: "将一个项目的 CI/CD 变量复制到另一个项目",
+	Long: `从 --from-group 下的 --from-project 读取全部 CI/CD 变量，复制到 --to-group 下的
+--to-project。目标项目已存在同名变量 (同一环境作用域) 时跳过而不覆盖。
+
+与 fork 命令的 --copy-ci-variables 等价，但用于两个已经存在的项目之间 (例如派生完成之后
+再补齐遗漏的变量)，而不要求本次调用同时执行派生。`,
+	Example: `  gitlab-fork-cli copy-variables --from-group fy-dev --from-project iris --to-group fy-prod --to-project iris
+  gitlab-fork-cli copy-variables --from-group fy-dev --from-project iris --to-group fy-prod --to-project iris --skip-protected --skip-masked`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if copyVariablesFromGroup == "" || copyVariablesFromProject == "" || copyVariablesToGroup == "" || copyVariablesToProject == "" {
+			logFatal("❌ 错误: 必须提供 --from-group、--from-project、--to-group、--to-project 参数。")
+		}
+
+		ctx := cmd.Context()
+		kubeRestConfig, err := k8sutil.GetKubeConfig()
+		if err != nil {
+			logFatalf("❌ 无法获取 Kubernetes 配置。错误: %v\n", err)
+		}
+
+		fromToken, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, copyVariablesFromGroup, GitlabSecretName, GitlabTokenKey)
+		if err != nil {
+			logFatalf("❌ 无法获取来源组 '%s' 的令牌: %v\n", copyVariablesFromGroup, err)
+		}
+		toToken, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, copyVariablesToGroup, GitlabSecretName, GitlabTokenKey)
+		if err != nil {
+			logFatalf("❌ 无法获取目标组 '%s' 的令牌: %v\n", copyVariablesToGroup, err)
+		}
+
+		fromGit, err := newGitLabClient(fromToken, baseURL, insecureSkip, caCertFile)
+		if err != nil {
+			logFatalf("❌ 创建来源 GitLab 客户端失败: %v\n", err)
+		}
+		toGit, err := newGitLabClient(toToken, baseURL, insecureSkip, caCertFile)
+		if err != nil {
+			logFatalf("❌ 创建目标 GitLab 客户端失败: %v\n", err)
+		}
+
+		fromGroupPath := getModelGroupByNs(copyVariablesFromGroup)
+		fromProjectID, err := findProjectInGroup(fromGit, fromGroupPath, copyVariablesFromProject, groupEnumFilter{includeArchived: true})
+		if err != nil {
+			logFatalf("❌ 在组 '%s' 中未找到项目 '%s'。错误: %v\n", fromGroupPath, copyVariablesFromProject, err)
+		}
+		toGroupPath := getModelGroupByNs(copyVariablesToGroup)
+		toProjectID, err := findProjectInGroup(toGit, toGroupPath, copyVariablesToProject, groupEnumFilter{includeArchived: true})
+		if err != nil {
+			logFatalf("❌ 在组 '%s' 中未找到项目 '%s'。错误: %v\n", toGroupPath, copyVariablesToProject, err)
+		}
+
+		if readOnlyGuard(fmt.Sprintf("将 '%s/%s' 的 CI/CD 变量复制到 '%s/%s'", fromGroupPath, copyVariablesFromProject, toGroupPath, copyVariablesToProject)) {
+			return
+		}
+
+		log.Printf("🚀 正在将 '%s/%s' 的 CI/CD 变量复制到 '%s/%s'...\n", fromGroupPath, copyVariablesFromProject, toGroupPath, copyVariablesToProject)
+		if err := pkg.CopyProjectVariablesFiltered(ctx, toGit, fromProjectID, toProjectID, copyVariablesSkipProtected, copyVariablesSkipMasked); err != nil {
+			logFatalf("❌ 复制变量失败: %v\n", err)
+		}
+		log.Println("✅ 变量复制完成。")
+	},
+}
+
+func init() {
+	copyVariablesCmd.Flags().StringVar(&copyVariablesFromGroup, "from-group", "", "来源项目所在的 NS 名称 (必填)")
+	copyVariablesCmd.Flags().StringVar(&copyVariablesFromProject, "from-project", "", "来源项目名称 (必填)")
+	copyVariablesCmd.Flags().StringVar(&copyVariablesToGroup, "to-group", "", "目标项目所在的 NS 名称 (必填)")
+	copyVariablesCmd.Flags().StringVar(&copyVariablesToProject, "to-project", "", "目标项目名称 (必填)")
+	copyVariablesCmd.Flags().BoolVar(&copyVariablesSkipProtected, "skip-protected", false, "跳过受保护变量 (通常只应在其原本被保护的分支/环境上使用)")
+	copyVariablesCmd.Flags().BoolVar(&copyVariablesSkipMasked, "skip-masked", false, "跳过 masked 变量 (取值往往就是密钥本身)")
+
+	copyVariablesCmd.MarkFlagRequired("from-group")
+	copyVariablesCmd.MarkFlagRequired("from-project")
+	copyVariablesCmd.MarkFlagRequired("to-group")
+	copyVariablesCmd.MarkFlagRequired("to-project")
+
+	rootCmd.AddCommand(copyVariablesCmd)
+}
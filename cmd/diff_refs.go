@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg"
+	"github.com/spf13/cobra"
+)
+
+// 定义 diff-refs 命令的参数变量，与 'mirror' 命令保持一致的 --from-*/--to-* 约定
+var (
+	diffRefsFromRepoURL   string
+	diffRefsFromProject   string
+	diffRefsFromToken     string
+	diffRefsFromTokenFile string
+	diffRefsToRepoURL     string
+	diffRefsToProject     string
+	diffRefsToToken       string
+	diffRefsToTokenFile   string
+	diffRefsPromptToken   bool
+	diffRefsFormat        string
+)
+
+// printRefSetDiffHuman 以人类可读的分组文本打印引用差异
+func printRefSetDiffHuman(diff *pkg.RefSetDiff) {
+	print := func(label string, refs []string) {
+		if len(refs) == 0 {
+			return
+		}
+		log.Printf("%s:\n", label)
+		for _, r := range refs {
+			log.Printf("  - %s\n", r)
+		}
+	}
+	print("仅源仓库存在的分支", diff.SourceOnlyBranches)
+	print("仅目标仓库存在的分支", diff.TargetOnlyBranches)
+	print("仅源仓库存在的标签", diff.SourceOnlyTags)
+	print("仅目标仓库存在的标签", diff.TargetOnlyTags)
+}
+
+// diffRefsCmd 定义了 'diff-refs' 子命令：对源/目标仓库分别执行 ls-remote，比较分支/标签集合，
+// 用于检测已产生漂移的镜像 (如上游删除了某个标签，但目标镜像仍保留)，而不必等 sync-fork/mirror
+// 实际执行一次推送才发现差异。
+var diffRefsCmd = &cobra.Command{
+	Use:   "diff-refs",
+	Short: "比较源/目标仓库的分支与标签集合差异",
+	Long: `对 --from-*/--to-* 指定的两个仓库分别执行 ls-remote (与 'mirror' 命令相同的仓库寻址约定)，
+比较两者的分支与标签集合，报告仅存在于一方的引用，用于检测已经产生漂移的镜像
+(例如上游删除了某个标签，但由 'mirror'/'sync-fork' 维护的目标仓库仍保留该标签)。
+本命令只读取引用列表，不做任何写操作。`,
+	Example: `  gitlab-fork-cli diff-refs --from-repo-url https://gitlab.example.com/dev/app.git --to-repo-url https://gitlab.example.com/prod/app.git
+  gitlab-fork-cli diff-refs --from-project dev/app --to-project prod/app --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if diffRefsFromRepoURL != "" && diffRefsFromProject != "" {
+			logFatal("❌ 错误: --from-repo-url 与 --from-project 只能提供一个。")
+		}
+		if diffRefsFromRepoURL == "" && diffRefsFromProject == "" {
+			logFatal("❌ 错误: 必须提供 --from-repo-url 或 --from-project 中的一个。")
+		}
+		if diffRefsFromProject != "" {
+			diffRefsFromRepoURL = buildRepoURLFromProject(baseURL, diffRefsFromProject)
+		}
+		if diffRefsToRepoURL != "" && diffRefsToProject != "" {
+			logFatal("❌ 错误: --to-repo-url 与 --to-project 只能提供一个。")
+		}
+		if diffRefsToRepoURL == "" && diffRefsToProject == "" {
+			logFatal("❌ 错误: 必须提供 --to-repo-url 或 --to-project 中的一个。")
+		}
+		if diffRefsToProject != "" {
+			diffRefsToRepoURL = buildRepoURLFromProject(baseURL, diffRefsToProject)
+		}
+		if diffRefsFormat != "human" && diffRefsFormat != "json" {
+			logFatalf("❌ 错误: --format 只能是 'human' 或 'json'，收到: %s\n", diffRefsFormat)
+		}
+
+		resolvedFromToken, err := resolveCloneToken(diffRefsFromToken, diffRefsFromTokenFile, gitlabFromTokenEnvVar, "源仓库令牌 (--from-token)", diffRefsPromptToken)
+		if err != nil {
+			logFatalf("❌ %v\n", err)
+		}
+		var fromAuth pkg.GitAuthMethod
+		if resolvedFromToken != "" {
+			fromAuth = &pkg.BasicAuthMethod{Username: "oauth2", Password: resolvedFromToken}
+		}
+
+		resolvedToToken, err := resolveCloneToken(diffRefsToToken, diffRefsToTokenFile, gitlabToTokenEnvVar, "目的仓库令牌 (--to-token)", diffRefsPromptToken)
+		if err != nil {
+			logFatalf("❌ %v\n", err)
+		}
+		var toAuth pkg.GitAuthMethod
+		if resolvedToToken != "" {
+			toAuth = &pkg.BasicAuthMethod{Username: "oauth2", Password: resolvedToToken}
+		}
+
+		ctx := cmd.Context()
+		var caBundle []byte
+		if caCertFile != "" {
+			caBundle, err = os.ReadFile(caCertFile)
+			if err != nil {
+				logFatalf("❌ 读取 CA 证书文件 '%s' 失败: %v\n", caCertFile, err)
+			}
+		}
+
+		diff, err := pkg.DiffRemoteRefs(ctx, diffRefsFromRepoURL, fromAuth, diffRefsToRepoURL, toAuth, insecureSkip, caBundle)
+		if err != nil {
+			logFatalf("❌ 比较引用失败: %v\n", err)
+		}
+
+		if diffRefsFormat == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(diff); err != nil {
+				logFatalf("❌ 序列化引用差异失败: %v\n", err)
+			}
+		} else if diff.Empty() {
+			log.Println("✅ 两个仓库的分支/标签集合完全一致，未发现漂移。")
+		} else {
+			printRefSetDiffHuman(diff)
+		}
+
+		if !diff.Empty() {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	diffRefsCmd.Flags().StringVar(&diffRefsFromRepoURL, "from-repo-url", "", "源 Git 仓库的完整 URL (与 --from-project 二选一，必填其一)")
+	diffRefsCmd.Flags().StringVar(&diffRefsFromProject, "from-project", "", "源项目路径 (如 'group/project')，由工具拼接 --base-url 得到完整 URL (与 --from-repo-url 二选一)")
+	diffRefsCmd.Flags().StringVar(&diffRefsFromToken, "from-token", "", "源仓库用于认证的个人访问令牌 (可选，公开仓库可省略)")
+	diffRefsCmd.Flags().StringVar(&diffRefsFromTokenFile, "from-token-file", "", "从文件读取源仓库令牌 (可选)")
+	diffRefsCmd.Flags().StringVar(&diffRefsToRepoURL, "to-repo-url", "", "目的 Git 仓库的完整 URL (与 --to-project 二选一，必填其一)")
+	diffRefsCmd.Flags().StringVar(&diffRefsToProject, "to-project", "", "目的项目路径 (如 'group/project')，由工具拼接 --base-url 得到完整 URL (与 --to-repo-url 二选一)")
+	diffRefsCmd.Flags().StringVar(&diffRefsToToken, "to-token", "", "目的仓库用于认证的个人访问令牌 (可选，公开仓库可省略)")
+	diffRefsCmd.Flags().StringVar(&diffRefsToTokenFile, "to-token-file", "", "从文件读取目的仓库令牌 (可选)")
+	diffRefsCmd.Flags().BoolVar(&diffRefsPromptToken, "prompt-token", false, "任一令牌未通过标志/文件提供时，交互式从终端读取 (不回显)")
+	diffRefsCmd.Flags().StringVar(&diffRefsFormat, "format", "human", "输出格式: human 或 json")
+
+	for _, name := range []string{"from-token", "to-token", "from-token-file", "to-token-file", "prompt-token"} {
+		categorizeFlag(diffRefsCmd, name, "auth")
+	}
+	categorizeFlag(diffRefsCmd, "from-project", "behavior")
+	categorizeFlag(diffRefsCmd, "to-project", "behavior")
+	categorizeFlag(diffRefsCmd, "format", "output")
+
+	rootCmd.AddCommand(diffRefsCmd)
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg"
+	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
+	"github.com/spf13/cobra"
+)
+
+// 定义 diff-settings 命令的参数变量
+var (
+	diffSettingsSourceGroup   string
+	diffSettingsSourceProject string
+	diffSettingsTargetGroup   string
+	diffSettingsTargetProject string
+	diffSettingsFormat        string
+)
+
+// printSettingsDiffHuman 以人类可读的分组文本打印差异，每条记录标注是仅源存在 (-)、
+// 仅目标存在 (+)、还是两边都存在但取值不同 (~)
+func printSettingsDiffHuman(diff *pkg.SettingsDiff) {
+	categories := []struct {
+		label string
+		diffs []pkg.FieldDiff
+	}{
+		{"CI/CD 变量", diff.Variables},
+		{"受保护分支", diff.Protections},
+		{"Webhooks", diff.Webhooks},
+		{"Push Rules", diff.PushRules},
+		{"成员", diff.Members},
+	}
+	for _, c := range categories {
+		if len(c.diffs) == 0 {
+			continue
+		}
+		fmt.Printf("## %s\n", c.label)
+		for _, d := range c.diffs {
+			switch d.Status {
+			case "removed":
+				fmt.Printf("  - %s (仅源项目存在): %s\n", d.Key, d.Source)
+			case "added":
+				fmt.Printf("  + %s (仅目标项目存在): %s\n", d.Key, d.Target)
+			case "changed":
+				fmt.Printf("  ~ %s: %s -> %s\n", d.Key, d.Source, d.Target)
+			}
+		}
+	}
+}
+
+// diffSettingsCmd 定义了 'diff-settings' 子命令，比较两个项目的晋级相关设置 (CI/CD 变量、
+// 受保护分支、webhooks、push rules、成员)，用于定期审计 dev/prod 等项目间的配置漂移。
+var diffSettingsCmd = &cobra.Command{
+	Use:   "diff-settings",
+	Short: "比较两个项目的设置差异",
+	Long: `分别导出 --source-group/--source-project 与 --target-group/--target-project 的设置快照
+(与 'export-settings' 使用相同的逻辑)，逐类别比较差异并输出。
+
+--format human (默认) 输出分组文本，--format json 输出结构化 JSON，便于接入定期审计流水线。
+两边都不存在的类别不会出现在输出中；只要有任意差异，命令会以非零状态码退出，便于在 CI 中
+用作"配置漂移检测"的把关步骤。`,
+	Example: `  gitlab-fork-cli diff-settings --source-group fy-dev --source-project iris --target-group fy-prod --target-project iris
+  gitlab-fork-cli diff-settings --source-group fy-dev --source-project iris --target-group fy-prod --target-project iris --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if diffSettingsSourceGroup == "" || diffSettingsSourceProject == "" || diffSettingsTargetGroup == "" || diffSettingsTargetProject == "" {
+			logFatal("❌ 错误: 必须提供 --source-group、--source-project、--target-group、--target-project 参数。")
+		}
+		if diffSettingsFormat != "human" && diffSettingsFormat != "json" {
+			logFatalf("❌ 错误: --format 只能是 'human' 或 'json'，收到: %s\n", diffSettingsFormat)
+		}
+
+		ctx := cmd.Context()
+		kubeRestConfig, err := k8sutil.GetKubeConfig()
+		if err != nil {
+			logFatalf("❌ 无法获取 Kubernetes 配置。错误: %v\n", err)
+		}
+
+		sourceToken, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, diffSettingsSourceGroup, GitlabSecretName, GitlabTokenKey)
+		if err != nil {
+			logFatalf("❌ 无法获取来源组 '%s' 的令牌: %v\n", diffSettingsSourceGroup, err)
+		}
+		targetToken, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, diffSettingsTargetGroup, GitlabSecretName, GitlabTokenKey)
+		if err != nil {
+			logFatalf("❌ 无法获取目标组 '%s' 的令牌: %v\n", diffSettingsTargetGroup, err)
+		}
+
+		sourceGit, err := newGitLabClient(sourceToken, baseURL, insecureSkip, caCertFile)
+		if err != nil {
+			logFatalf("❌ 创建来源 GitLab 客户端失败: %v\n", err)
+		}
+		targetGit, err := newGitLabClient(targetToken, baseURL, insecureSkip, caCertFile)
+		if err != nil {
+			logFatalf("❌ 创建目标 GitLab 客户端失败: %v\n", err)
+		}
+
+		sourceGroupPath := getModelGroupByNs(diffSettingsSourceGroup)
+		sourceProjectID, err := findProjectInGroup(sourceGit, sourceGroupPath, diffSettingsSourceProject, groupEnumFilter{includeArchived: true})
+		if err != nil {
+			logFatalf("❌ 在组 '%s' 中未找到项目 '%s'。错误: %v\n", sourceGroupPath, diffSettingsSourceProject, err)
+		}
+		targetGroupPath := getModelGroupByNs(diffSettingsTargetGroup)
+		targetProjectID, err := findProjectInGroup(targetGit, targetGroupPath, diffSettingsTargetProject, groupEnumFilter{includeArchived: true})
+		if err != nil {
+			logFatalf("❌ 在组 '%s' 中未找到项目 '%s'。错误: %v\n", targetGroupPath, diffSettingsTargetProject, err)
+		}
+
+		sourceSnapshot, err := pkg.ExportSettingsSnapshot(ctx, sourceGit, sourceProjectID, fmt.Sprintf("%s/%s", sourceGroupPath, diffSettingsSourceProject), false)
+		if err != nil {
+			logFatalf("❌ 导出来源项目设置失败: %v\n", err)
+		}
+		targetSnapshot, err := pkg.ExportSettingsSnapshot(ctx, targetGit, targetProjectID, fmt.Sprintf("%s/%s", targetGroupPath, diffSettingsTargetProject), false)
+		if err != nil {
+			logFatalf("❌ 导出目标项目设置失败: %v\n", err)
+		}
+
+		diff := pkg.DiffSettingsSnapshots(sourceSnapshot, targetSnapshot)
+
+		if diffSettingsFormat == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(diff); err != nil {
+				logFatalf("❌ 序列化差异失败: %v\n", err)
+			}
+		} else if diff.Empty() {
+			log.Println("✅ 两个项目的设置完全一致，未发现漂移。")
+		} else {
+			printSettingsDiffHuman(diff)
+		}
+
+		if !diff.Empty() {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	diffSettingsCmd.Flags().StringVar(&diffSettingsSourceGroup, "source-group", "", "源项目所在的 NS 名称 (必填)")
+	diffSettingsCmd.Flags().StringVar(&diffSettingsSourceProject, "source-project", "", "源项目名称 (必填)")
+	diffSettingsCmd.Flags().StringVar(&diffSettingsTargetGroup, "target-group", "", "目标项目所在的 NS 名称 (必填)")
+	diffSettingsCmd.Flags().StringVar(&diffSettingsTargetProject, "target-project", "", "目标项目名称 (必填)")
+	diffSettingsCmd.Flags().StringVar(&diffSettingsFormat, "format", "human", "输出格式: human 或 json")
+
+	categorizeFlag(diffSettingsCmd, "format", "output")
+
+	diffSettingsCmd.MarkFlagRequired("source-group")
+	diffSettingsCmd.MarkFlagRequired("source-project")
+	diffSettingsCmd.MarkFlagRequired("target-group")
+	diffSettingsCmd.MarkFlagRequired("target-project")
+
+	rootCmd.AddCommand(diffSettingsCmd)
+}
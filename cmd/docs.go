@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// 定义 docs 命令的参数变量
+var docsOutputDir string
+
+// docsCmd 定义了 'docs' 子命令，用于从 cobra 命令树生成 Markdown 文档
+var docsCmd = &cobra.Command{
+	Use:    "docs",
+	Short:  "为所有子命令生成 Markdown 文档",
+	Hidden: true,
+	Long: `此命令遍历 cobra 命令树，为每个子命令生成一份 Markdown 文档 (用法、标志、示例)，
+写入 --output-dir 指定的目录，用于保持文档与实际标志同步，避免帮助信息中出现已废弃的标志。`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := os.MkdirAll(docsOutputDir, 0o755); err != nil {
+			logFatalf("❌ 创建输出目录 '%s' 失败: %v\n", docsOutputDir, err)
+		}
+		if err := genMarkdownTree(rootCmd, docsOutputDir); err != nil {
+			logFatalf("❌ 生成文档失败: %v\n", err)
+		}
+		log.Printf("✅ 文档已生成到目录: %s\n", docsOutputDir)
+	},
+}
+
+// genMarkdownTree 递归地为 cmd 及其所有子命令各生成一份 Markdown 文档
+func genMarkdownTree(cmd *cobra.Command, dir string) error {
+	for _, child := range cmd.Commands() {
+		if !child.IsAvailableCommand() || child.IsAdditionalHelpTopicCommand() {
+			continue
+		}
+		if err := genMarkdownTree(child, dir); err != nil {
+			return err
+		}
+	}
+
+	filename := filepath.Join(dir, strings.ReplaceAll(cmd.CommandPath(), " ", "_")+".md")
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("创建文件 '%s' 失败: %w", filename, err)
+	}
+	defer f.Close()
+
+	fmt.Fprintf(f, "## %s\n\n%s\n\n", cmd.CommandPath(), cmd.Short)
+	if cmd.Long != "" {
+		fmt.Fprintf(f, "%s\n\n", cmd.Long)
+	}
+	if cmd.HasExample() {
+		fmt.Fprintf(f, "### 示例\n\n```\n%s\n```\n\n", cmd.Example)
+	}
+	if cmd.HasAvailableLocalFlags() {
+		fmt.Fprintf(f, "### 标志\n\n```\n%s\n```\n\n", strings.TrimRight(cmd.LocalFlags().FlagUsages(), "\n"))
+	}
+	return nil
+}
+
+func init() {
+	docsCmd.Flags().StringVar(&docsOutputDir, "output-dir", "./docs", "生成的 Markdown 文档写入的目录")
+
+	rootCmd.AddCommand(docsCmd)
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg"
+	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// 定义 export-settings 命令的参数变量
+var (
+	exportSettingsGroup         string
+	exportSettingsProject       string
+	exportSettingsOutput        string
+	exportSettingsRedactSecrets bool
+)
+
+// exportSettingsCmd 定义了 'export-settings' 子命令，将一个项目的晋级相关设置 (CI/CD 变量、
+// 受保护分支、webhooks、push rules、成员) 导出为一份可读的 YAML 快照，供备份、审阅比对、
+// 或通过 'apply-settings' 迁移到另一个项目使用。
+var exportSettingsCmd = &cobra.Command{
+	Use:   "export-settings",
+	Short: "导出一个项目的晋级相关设置为 YAML 快照",
+	Long: `导出 --group 下 --project 的 CI/CD 变量、受保护分支、webhooks、push rules、成员，
+汇总为一份 YAML 快照写入 --output (省略时打印到标准输出)。
+
+默认包含变量的明文取值；--redact-secrets 时变量取值一律替换为 "***" 占位符，
+适用于快照需要提交到版本库审阅、而不希望明文密钥随之落盘的场景。`,
+	Example: `  gitlab-fork-cli export-settings --group fy-dev --project iris --output iris-settings.yaml
+  gitlab-fork-cli export-settings --group fy-dev --project iris --redact-secrets`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if exportSettingsGroup == "" || exportSettingsProject == "" {
+			logFatal("❌ 错误: 必须提供 --group 和 --project 参数。")
+		}
+
+		ctx := cmd.Context()
+		kubeRestConfig, err := k8sutil.GetKubeConfig()
+		if err != nil {
+			logFatalf("❌ 无法获取 Kubernetes 配置。错误: %v\n", err)
+		}
+
+		token, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, exportSettingsGroup, GitlabSecretName, GitlabTokenKey)
+		if err != nil {
+			logFatalf("❌ 无法获取组 '%s' 的令牌。错误: %v\n", exportSettingsGroup, err)
+		}
+		git, err := newGitLabClient(token, baseURL, insecureSkip, caCertFile)
+		if err != nil {
+			logFatalf("❌ 创建 GitLab 客户端失败: %v\n", err)
+		}
+
+		groupPath := getModelGroupByNs(exportSettingsGroup)
+		projectID, err := findProjectInGroup(git, groupPath, exportSettingsProject, groupEnumFilter{includeArchived: true})
+		if err != nil {
+			logFatalf("❌ 在组 '%s' 中未找到项目 '%s'。错误: %v\n", groupPath, exportSettingsProject, err)
+		}
+
+		log.Printf("ℹ️ 正在导出项目 '%s/%s' (ID: %d) 的设置...\n", groupPath, exportSettingsProject, projectID)
+		snapshot, err := pkg.ExportSettingsSnapshot(ctx, git, projectID, fmt.Sprintf("%s/%s", groupPath, exportSettingsProject), exportSettingsRedactSecrets)
+		if err != nil {
+			logFatalf("❌ 导出设置失败: %v\n", err)
+		}
+
+		data, err := yaml.Marshal(snapshot)
+		if err != nil {
+			logFatalf("❌ 序列化快照失败: %v\n", err)
+		}
+
+		if exportSettingsOutput == "" || exportSettingsOutput == "-" {
+			os.Stdout.Write(data)
+			return
+		}
+		if err := os.WriteFile(exportSettingsOutput, data, 0o644); err != nil {
+			logFatalf("❌ 写入快照文件 '%s' 失败: %v\n", exportSettingsOutput, err)
+		}
+		log.Printf("✅ 快照已写入 '%s'。\n", exportSettingsOutput)
+	},
+}
+
+func init() {
+	exportSettingsCmd.Flags().StringVar(&exportSettingsGroup, "group", "", "项目所在的 NS 名称 (必填)")
+	exportSettingsCmd.Flags().StringVar(&exportSettingsProject, "project", "", "要导出设置的项目名称 (必填)")
+	exportSettingsCmd.Flags().StringVar(&exportSettingsOutput, "output", "", "快照写入的文件路径，省略或为 '-' 时打印到标准输出")
+	exportSettingsCmd.Flags().BoolVar(&exportSettingsRedactSecrets, "redact-secrets", false, "变量取值一律替换为 '***' 占位符，适用于快照需要提交到版本库审阅的场景")
+
+	exportSettingsCmd.MarkFlagRequired("group")
+	exportSettingsCmd.MarkFlagRequired("project")
+
+	rootCmd.AddCommand(exportSettingsCmd)
+}
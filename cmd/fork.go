@@ -1,15 +1,29 @@
 package cmd
 
 import (
+	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"github.com/fy1316/gitlab-fork-cli/pkg"
 	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
 	"log"
 	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"golang.org/x/time/rate"
+	"gopkg.in/yaml.v3"
+	"k8s.io/client-go/rest"
 )
 
 // 定义 fork 命令的参数变量
@@ -19,9 +33,159 @@ var (
 	targetGroup   string
 	devToken      string
 	prodToken     string
+
+	// 已废弃标志的兼容层：新的令牌解析方式改为从 k8s Secret 自动获取，
+	// 但保留这些旧标志名以免破坏现有自动化脚本，设置时优先于自动解析的令牌
+	devTokenOverride  string
+	prodTokenOverride string
+
+	// --no-k8s: 完全跳过 Kubernetes 依赖 (命名空间存在性检查、Secret 令牌解析、幂等标记读写)，
+	// 令牌改为强制要求通过 --dev-token/--prod-token 直接提供，用于集群外环境
+	noK8s bool
+
+	// 组枚举时的排除规则 (glob 模式)，用于批量派生时跳过归档/沙箱子组或项目
+	excludeSubgroupPatterns []string
+	excludeProjectPatterns  []string
+	includeArchived         bool
+	topicFilter             []string
+
+	// 派生完成后在新项目上创建徽章/链接，方便使用者从生产仓库跳转回监控面板与溯源信息
+	provisionBadges  bool
+	dashboardBaseURL string
+
+	// 派生完成后在新项目上创建 CD 工具期望存在的 environments，并可选保护生产环境
+	setupEnvironments    bool
+	protectProductionEnv bool
+
+	// 派生完成后在新项目上写入记录本次派生来源的 CI/CD 变量 (来源项目、来源提交 SHA、推广标签)，
+	// 使新项目自身的流水线无需调用外部系统即可获知溯源信息
+	provisionCIVariables bool
+	promotedTag          string
+
+	// 目标子组 (如 '<ns>/amlmodels') 不存在时自动创建，避免因子组尚未预先创建而派生失败
+	ensureTargetGroup            bool
+	ensureTargetGroupVisibility  string
+	ensureTargetGroupDescription string
+
+	// 选择性设置复制策略文件：描述从源项目复制哪些类别的设置 (变量、受保护分支、webhooks、
+	// 成员、徽章、元数据) 到新派生项目，取代逐个新增 --copy-xxx 布尔标志的方式
+	settingsProfilePath string
+
+	// 派生完成后将源项目的 CI/CD 变量复制到新项目，因为 GitLab 的 ForkProject API 本身不会带过去，
+	// 与 --settings-profile 中的 variables 类别等价，但更轻量、无需额外的策略文件
+	copyCIVariables              bool
+	copyCIVariablesSkipProtected bool
+	copyCIVariablesSkipMasked    bool
+
+	// 派生完成后将源项目的受保护分支/受保护标签规则复制到新项目，因为 GitLab 的 ForkProject
+	// API 本身不会带过去，与 --settings-profile 中的 protections 类别等价，但更轻量、
+	// 无需额外的策略文件
+	copyProtections bool
+
+	// 派生完成后将源项目的 webhooks 复制到新项目；webhook 的密钥 token 无法通过 API 读回，
+	// 因此额外支持一份按 URL 映射 token 的文件用来补全
+	copyWebhooks       bool
+	webhookSecretsFile string
+
+	// 派生完成后将源项目的部署密钥复制到新项目
+	copyDeployKeys bool
+
+	// 派生完成后将源项目的直接成员与共享群组复制到新项目，minAccessLevel 用于跳过 guest 等
+	// 低权限成员 (不影响共享群组，群组共享没有"guest 群组"这个概念)
+	copyMembers               bool
+	copyMembersMinAccessLevel string
+
+	// 目标组中已存在同名项目时的处理策略
+	onNameConflict string
+
+	// 目标组中已存在同名项目时是否直接以成功退出 (skip/reuse)，使工具在带重试语义的流水线中
+	// 可以安全地重复调用；默认 fail 保持与此前完全一致的行为 (交由 onNameConflict 决定)
+	ifExistsPolicy string
+
+	// 幂等键：用于让基于"至少一次"语义的调度器安全地重试同一次派生请求，
+	// 而不会因为重复触发而产生第二次派生
+	idempotencyKey string
+
+	// 目标项目命名规范：--target-name 显式指定目标项目名/路径；未指定时按 --name-template 生成，
+	// 生成或指定的名称都必须匹配 --name-pattern，用于强制统一的生产命名约定
+	targetName   string
+	nameTemplate string
+	namePattern  string
+
+	// --target-project-name/--target-path 分别单独覆盖新项目的显示名/路径 (URL slug)，用于两者
+	// 需要不同取值的场景 (如生产环境路径带 "-prod" 后缀但显示名保持不变)；省略时分别回退到
+	// --target-name/--name-template 解析出的名称。与 --target-name 不同，这两个标志只影响
+	// 新项目本身的 Name/Path 字段，不参与目标组内的同名冲突检测 (冲突检测仍按 --target-name 进行)。
+	targetProjectDisplayName string
+	targetPath               string
+
+	// 派生成功后为新项目打上的 topics，支持 {source}/{env} 占位符 (如 "source:{source}")，
+	// 便于通过 GitLab 的 topic 搜索发现所有已被派生/推广的项目
+	forkTopics []string
+
+	// 派生成功后单独设置新项目的可见性，覆盖 ForkProject 默认继承自源项目的可见性 (如生产环境
+	// 需要强制 private，即使源项目是 internal/public)
+	targetVisibility string
+
+	// 派生成功后调用 DeleteProjectForkRelation 移除新项目与源项目的派生关系，用于合规要求
+	// 生产副本与上游代码库脱钩的场景；跨实例派生模式下新项目本就通过创建空项目+镜像推送产生，
+	// 从未建立派生关系，该标志对该模式无意义
+	breakForkRelationship bool
+
+	// dry-run: 完成所有校验 (命名空间检查、令牌获取、源项目查找、目标冲突检查) 后只打印计划执行的操作，
+	// 不调用 ForkProject，用于在自动化流水线中安全地预演一次派生
+	dryRun bool
+
+	// 新项目描述的溯源模板：记录来源路径、推广人、日期、关联工单，便于在 GitLab 上直接看到项目来源；
+	// --description 提供时完全覆盖模板生成的内容
+	forkDescription     string
+	descriptionTemplate string
+	promotedBy          string
+	ticketLabel         string
+
+	// 派生返回 201 后，新仓库的导入可能仍在后台进行，此时立即对其执行 git 操作会失败；
+	// --wait 时轮询新项目的 import_status 直到 "finished" 或失败状态，超过 --wait-timeout 则报错退出
+	waitForImport  bool
+	waitTimeout    time.Duration
+	waitPollPeriod time.Duration
+
+	// 管理员令牌 (kubeflow 命名空间下的令牌) 使用策略：never (禁止使用，权限不足直接报错)、
+	// fallback (仅当生产令牌权限不足时降级使用，并记录一次升级日志)、always (始终使用，等价于历史行为)
+	useAdminTokenPolicy string
+
+	// 精确指定源项目：--source-project-id 优先于按名称搜索，避免组内重名项目导致的歧义；
+	// --source-project 也可以直接传入 "group/subgroup/project" 形式的完整路径
+	sourceProjectIDFlag int
+
+	// --manifest 批量派生：从 YAML 文件读取一组 {source-group, source-project, target-group} 条目，
+	// 以 --manifest-concurrency 指定的并发度逐个执行，其余单次派生标志 (如 --dry-run、--wait) 对每个条目统一生效
+	forkManifestPath    string
+	manifestConcurrency int
+
+	// 跨实例派生：源/目标项目分别位于不同的 GitLab 实例上时，GitLab 的 ForkProject API 无法跨实例调用，
+	// 省略时均回退到全局 --base-url，行为与之前完全一致 (同实例派生)
+	sourceBaseURLFlag string
+	targetBaseURLFlag string
 )
 
+// defaultDescriptionTemplate 是未显式提供 --description 时用于生成新项目描述的默认模板，
+// 支持 {source}/{promoted_by}/{date}/{ticket} 占位符。
+const defaultDescriptionTemplate = "Forked from {source} on {date} by {promoted_by} (ticket: {ticket})"
+
+// 记录幂等派生结果所使用的命名空间 annotation key
 const (
+	idempotencyKeyAnnotation    = "gitlab-fork-cli/idempotency-key"
+	idempotencyResultAnnotation = "gitlab-fork-cli/idempotency-result"
+)
+
+// lastCorrelationIDAnnotation 记录触发最近一次派生的关联 ID (--correlation-id)，
+// 用于将目标命名空间与触发该次派生的日志/流水线运行关联起来
+const lastCorrelationIDAnnotation = "gitlab-fork-cli/last-correlation-id"
+
+// GitlabSecretName/GitlabTokenKey/amlModelsGroup 原为硬编码常量，现改为可通过 --config 指定的
+// 配置文件覆盖的变量 (见 config.go 中的 loadGlobalConfigDefaults)，使同一份二进制文件可以适配
+// 不同环境而无需重新编译。
+var (
 	GitlabSecretName = "aml-image-builder-secret"
 	GitlabTokenKey   = "MODEL_REPO_GIT_TOKEN"
 	amlModelsGroup   = "amlmodels"
@@ -31,8 +195,58 @@ func getModelGroupByNs(ns string) string {
 	return ns + "/" + amlModelsGroup
 }
 
-// newGitLabClient 封装了 GitLab 客户端的创建逻辑
-func newGitLabClient(token, baseURL string, insecureSkipVerify bool) (*gitlab.Client, error) {
+// groupEnumFilter 描述组枚举时应用的排除规则
+type groupEnumFilter struct {
+	excludeSubgroups []string
+	excludeProjects  []string
+	includeArchived  bool
+	topics           []string
+}
+
+// hasAnyTopic 判断项目的 topics (含已废弃的 tag_list) 是否包含 topics 中的任意一个
+func hasAnyTopic(p *gitlab.Project, topics []string) bool {
+	projectTopics := append(append([]string{}, p.Topics...), p.TagList...)
+	for _, want := range topics {
+		if slices.Contains(projectTopics, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesGlobAny 判断 name 是否匹配 patterns 中的任意一个 glob 模式
+func matchesGlobAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		if ok, err := path.Match(pattern, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// excludes 判断给定项目是否应被 filter 排除
+func (f groupEnumFilter) excludes(p *gitlab.Project) (bool, string) {
+	if p.Archived && !f.includeArchived {
+		return true, fmt.Sprintf("项目 '%s' 已归档 (使用 --include-archived 可覆盖此行为)", p.Path)
+	}
+	if matchesGlobAny(p.Path, f.excludeProjects) {
+		return true, fmt.Sprintf("项目 '%s' 匹配 --exclude-project 规则", p.Path)
+	}
+	if p.Namespace != nil && matchesGlobAny(p.Namespace.Path, f.excludeSubgroups) {
+		return true, fmt.Sprintf("项目 '%s' 所在子组 '%s' 匹配 --exclude-subgroup 规则", p.Path, p.Namespace.Path)
+	}
+	if len(f.topics) > 0 && !hasAnyTopic(p, f.topics) {
+		return true, fmt.Sprintf("项目 '%s' 未包含 --topic 指定的任一 topic", p.Path)
+	}
+	return false, ""
+}
+
+// newGitLabClient 封装了 GitLab 客户端的创建逻辑；caCertFile 非空时加载该文件作为附加的
+// CA 证书 (与系统证书池一并使用)，用于内部自签发 CA 签发的证书场景，无需为此整体启用 --insecure
+func newGitLabClient(token, baseURL string, insecureSkipVerify bool, caCertFile string) (*gitlab.Client, error) {
 	var httpClient *http.Client
 	if insecureSkipVerify {
 		httpClient = &http.Client{
@@ -40,21 +254,53 @@ func newGitLabClient(token, baseURL string, insecureSkipVerify bool) (*gitlab.Cl
 				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 			},
 		}
+	} else if caCertFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		caCert, err := os.ReadFile(caCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取 CA 证书文件 '%s' 失败: %w", caCertFile, err)
+		}
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("CA 证书文件 '%s' 不包含有效的 PEM 编码证书", caCertFile)
+		}
+		httpClient = &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: pool},
+			},
+		}
 	}
 
-	client, err := gitlab.NewClient(
-		token,
+	clientOptions := []gitlab.ClientOptionFunc{
 		gitlab.WithBaseURL(baseURL),
 		gitlab.WithHTTPClient(httpClient),
-	)
+	}
+	if correlationID != "" {
+		// 将本次运行的关联 ID 附加到每一次 GitLab API 请求上，便于事后在 GitLab 审计日志中
+		// 按该 ID 检索出同一次操作触发的全部请求
+		clientOptions = append(clientOptions, gitlab.WithRequestOptions(gitlab.WithHeader("X-Correlation-ID", correlationID)))
+	}
+	if maxRPS > 0 {
+		// 底层客户端库本身已经会根据响应的 RateLimit-Limit/RateLimit-Reset 头自动推算限流速率
+		// 并在命中 429 时按 RateLimit-Reset 退避重试；--max-rps 用于在此基础上额外设置一个硬上限，
+		// 用于枚举大型组等分页场景下主动收敛请求速率，而不是等触发限流之后才被动退避。
+		burst := int(maxRPS)
+		if burst < 1 {
+			burst = 1
+		}
+		clientOptions = append(clientOptions, gitlab.WithCustomLimiter(rate.NewLimiter(rate.Limit(maxRPS), burst)))
+	}
+	client, err := gitlab.NewClient(token, clientOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("创建 GitLab 客户端失败: %w", err)
 	}
 	return client, nil
 }
 
-// findProjectInGroup 在指定组中查找项目并返回其 ID
-func findProjectInGroup(client *gitlab.Client, groupID string, projectName string) (int, error) {
+// findProjectInGroup 在指定组中查找项目并返回其 ID，filter 为空值时不做任何排除
+func findProjectInGroup(client *gitlab.Client, groupID string, projectName string, filter groupEnumFilter) (int, error) {
 	listOptions := &gitlab.ListGroupProjectsOptions{}
 	listOptions.PerPage = 100
 	listOptions.IncludeSubGroups = gitlab.Ptr(true)
@@ -71,6 +317,10 @@ func findProjectInGroup(client *gitlab.Client, groupID string, projectName strin
 
 		for _, p := range projects {
 			if p.Name == projectName {
+				if excluded, reason := filter.excludes(p); excluded {
+					fmt.Printf("⏭️  跳过项目 '%s': %s\n", p.NameWithNamespace, reason)
+					continue
+				}
 				fmt.Printf("✅ 找到源项目: %s (ID: %d) 在组 '%s'\n", p.NameWithNamespace, p.ID, groupID)
 				return p.ID, nil
 			}
@@ -86,146 +336,996 @@ func findProjectInGroup(client *gitlab.Client, groupID string, projectName strin
 	return -1, fmt.Errorf("在组 '%s' 中未找到项目 '%s'", groupID, projectName)
 }
 
+// renderNameTemplate 将命名模板中的占位符替换为实际值，目前支持 {source} (源项目名) 与 {env} (目标组名) 两个占位符，
+// 用于在未显式指定 --target-name 时按统一约定 (如 "{source}-{env}") 生成目标项目名。
+func renderNameTemplate(tmpl, source, env string) string {
+	return strings.NewReplacer("{source}", source, "{env}", env).Replace(tmpl)
+}
+
+// renderDescriptionTemplate 将描述模板中的占位符替换为实际值，promoted_by/ticket 为空时分别
+// 填充为 "unknown"/"n/a" 占位，避免生成的描述中出现空洞的字面量文本。
+func renderDescriptionTemplate(tmpl, source, promotedBy, ticket, date string) string {
+	if promotedBy == "" {
+		promotedBy = "unknown"
+	}
+	if ticket == "" {
+		ticket = "n/a"
+	}
+	return strings.NewReplacer("{source}", source, "{promoted_by}", promotedBy, "{ticket}", ticket, "{date}", date).Replace(tmpl)
+}
+
+// validateProjectName 校验项目名是否匹配生产命名规范的正则表达式，pattern 为空时不做任何校验
+func validateProjectName(name, pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("命名规范正则 '%s' 编译失败: %w", pattern, err)
+	}
+	if !re.MatchString(name) {
+		return fmt.Errorf("目标项目名 '%s' 不符合生产命名规范 (需匹配正则 '%s')", name, pattern)
+	}
+	return nil
+}
+
+// requiredForkAccessLevel 是在目标组下派生项目所需的最低角色，与 GitLab 要求在目标命名空间下
+// 至少具有 Developer 权限才能创建/派生项目保持一致。
+const requiredForkAccessLevel = gitlab.DeveloperPermissions
+
+// accessLevelName 返回 AccessLevelValue 对应的可读角色名称，未在已知映射中时返回其数值
+func accessLevelName(level gitlab.AccessLevelValue) string {
+	for name, lvl := range groupAccessLevelByName {
+		if lvl == level {
+			return name
+		}
+	}
+	return fmt.Sprintf("%d", level)
+}
+
+// reportGroupPermission 在生产令牌派生失败 (403) 时，查询该令牌对应用户在目标组中的实际角色并与
+// 所需角色对比后打印，帮助操作者在管理员降级发生前就能定位到具体是谁、缺了哪一级权限。
+func reportGroupPermission(client *gitlab.Client, groupPath string, required gitlab.AccessLevelValue) {
+	currentUser, _, err := client.Users.CurrentUser()
+	if err != nil {
+		log.Printf("⚠️ 无法确定生产令牌对应的用户，跳过权限报告: %v\n", err)
+		return
+	}
+	member, _, err := client.GroupMembers.GetGroupMember(groupPath, currentUser.ID)
+	if err != nil {
+		log.Printf("ℹ️ 权限报告: 生产令牌对应用户 '%s' 在目标组 '%s' 中没有可查询到的成员身份 (可能完全没有访问权限，或权限继承自尚未展开查询的上级组)，本次派生要求至少 '%s' 角色。\n",
+			currentUser.Username, groupPath, accessLevelName(required))
+		return
+	}
+	log.Printf("ℹ️ 权限报告: 生产令牌对应用户 '%s' 在目标组 '%s' 中的当前角色为 '%s'，本次派生要求至少 '%s' 角色。\n",
+		currentUser.Username, groupPath, accessLevelName(member.AccessLevel), accessLevelName(required))
+}
+
+// resolveNameConflictBySuffix 在目标组中已存在同名项目时，依次尝试为项目名/路径追加 "-2"、"-3" ...
+// 后缀，直到找到一个尚未被占用的名称，用于支持同一模型的并行 A/B 派生。
+func resolveNameConflictBySuffix(client *gitlab.Client, targetGroupPath, baseName string) (string, error) {
+	for suffix := 2; suffix < 1000; suffix++ {
+		candidate := fmt.Sprintf("%s-%d", baseName, suffix)
+		_, err := findProjectInGroup(client, targetGroupPath, candidate, groupEnumFilter{includeArchived: true})
+		if err != nil && strings.Contains(err.Error(), "未找到项目") {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("检查候选名称 '%s' 是否可用失败: %w", candidate, err)
+		}
+	}
+	return "", fmt.Errorf("尝试到 -999 后缀仍未找到可用名称，基础名称: '%s'", baseName)
+}
+
+// ensureTargetGroupExists 在 --ensure-target-group 启用时，于派生前检查目标子组 (如 '<ns>/amlmodels')
+// 是否存在，不存在则以 visibility/description 在父命名空间 (ns 对应的顶层组) 下自动创建，
+// 避免因子组尚未预先创建而在稍后的项目枚举/派生步骤中收到 404
+func ensureTargetGroupExists(client *gitlab.Client, targetGroupPath, visibility, description string) error {
+	if _, _, err := client.Groups.GetGroup(targetGroupPath, nil); err == nil {
+		log.Printf("ℹ️ 目标组 '%s' 已存在，跳过创建。\n", targetGroupPath)
+		return nil
+	} else if resp, ok := err.(*gitlab.ErrorResponse); !ok || resp.Response == nil || resp.Response.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("查询目标组 '%s' 是否存在失败: %w", targetGroupPath, err)
+	}
+
+	parentPath := path.Dir(targetGroupPath)
+	groupName := path.Base(targetGroupPath)
+	if parentPath == "." || parentPath == "" {
+		return fmt.Errorf("目标组 '%s' 不是子组路径 (期望形如 'namespace/subgroup')，无法自动创建", targetGroupPath)
+	}
+	parent, _, err := client.Groups.GetGroup(parentPath, nil)
+	if err != nil {
+		return fmt.Errorf("目标组不存在，尝试自动创建时查询父组 '%s' 失败: %w", parentPath, err)
+	}
+
+	log.Printf("🚀 目标组 '%s' 不存在，正在于父组 '%s' (ID: %d) 下创建 (可见性: %s)...\n", targetGroupPath, parentPath, parent.ID, visibility)
+	newGroup, _, err := client.Groups.CreateGroup(&gitlab.CreateGroupOptions{
+		Name:        gitlab.Ptr(groupName),
+		Path:        gitlab.Ptr(groupName),
+		ParentID:    gitlab.Ptr(parent.ID),
+		Visibility:  gitlab.Ptr(gitlab.VisibilityValue(visibility)),
+		Description: gitlab.Ptr(description),
+	})
+	if err != nil {
+		return fmt.Errorf("创建目标组 '%s' 失败: %w", targetGroupPath, err)
+	}
+	log.Printf("✅ 目标组创建成功: %s (ID: %d)\n", newGroup.FullPath, newGroup.ID)
+	return nil
+}
+
+// loadSettingsProfile 读取并解析 --settings-profile 指定的来源 (与 --manifest 支持相同的来源形式：
+// 本地文件路径、"-"、"http(s)://" URL、"configmap://<namespace>/<name>/<key>") 中的 YAML 内容，
+// 描述要从源项目复制到新派生项目的设置类别，取代逐个新增 --copy-xxx 布尔标志的方式。
+func loadSettingsProfile(ctx context.Context, source string) (pkg.SettingsProfile, error) {
+	data, err := readSource(ctx, source)
+	if err != nil {
+		return pkg.SettingsProfile{}, fmt.Errorf("读取 settings-profile '%s' 失败: %w", source, err)
+	}
+
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return pkg.SettingsProfile{}, fmt.Errorf("解析 settings-profile '%s' 失败: %w", source, err)
+	}
+	if err := pkg.ValidateAgainstSchema("settings-profile", raw); err != nil {
+		return pkg.SettingsProfile{}, fmt.Errorf("settings-profile '%s' 不符合 settings-profile schema: %w", source, err)
+	}
+
+	var profile pkg.SettingsProfile
+	if err := yaml.Unmarshal(data, &profile); err != nil {
+		return pkg.SettingsProfile{}, fmt.Errorf("解析 settings-profile '%s' 失败: %w", source, err)
+	}
+	return profile, nil
+}
+
+// loadWebhookSecretsMapping 读取一份按源项目 webhook URL 映射密钥 token 的 YAML 文件
+// (格式为 {url: token} 的简单映射)，用于弥补 GitLab API 无法读回已配置 webhook token 的限制。
+func loadWebhookSecretsMapping(ctx context.Context, source string) (map[string]string, error) {
+	data, err := readSource(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("读取 webhook 密钥映射文件 '%s' 失败: %w", source, err)
+	}
+	var mapping map[string]string
+	if err := yaml.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("解析 webhook 密钥映射文件 '%s' 失败: %w", source, err)
+	}
+	return mapping, nil
+}
+
+// forkManifestEntry 描述 --manifest 文件中的一条批量派生条目
+type forkManifestEntry struct {
+	SourceGroup   string `yaml:"source-group"`
+	SourceProject string `yaml:"source-project"`
+	TargetGroup   string `yaml:"target-group"`
+}
+
+// forkManifestResult 记录批量派生中单个条目的执行结果
+type forkManifestResult struct {
+	Entry   forkManifestEntry
+	Success bool
+	Output  string
+	Err     error
+}
+
+// loadForkManifest 读取并解析 --manifest 指定的来源 (本地文件路径，或 "-"/"http(s)://"/"configmap://" 等
+// readSource 支持的其他来源) 中的 YAML 内容
+func loadForkManifest(ctx context.Context, source string) ([]forkManifestEntry, error) {
+	data, err := readSource(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("读取 manifest '%s' 失败: %w", source, err)
+	}
+
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("解析 manifest '%s' 失败: %w", source, err)
+	}
+	if err := pkg.ValidateAgainstSchema("fork-manifest", raw); err != nil {
+		return nil, fmt.Errorf("manifest '%s' 不符合 fork-manifest schema: %w", source, err)
+	}
+
+	var entries []forkManifestEntry
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("解析 manifest '%s' 失败: %w", source, err)
+	}
+	return entries, nil
+}
+
+// passthroughForkFlags 收集本次调用中除批量模式自身、三个按条目指定的标志以及 --source-project-id 外、
+// 被显式设置的其余 fork 标志，原样透传给每个条目对应的子调用 (列表类标志按元素逐个重复传递)，从而使批量
+// 派生与单次派生共享同一套行为开关 (如 --dry-run、--wait、--provision-badges)。
+func passthroughForkFlags(cmd *cobra.Command) []string {
+	skip := map[string]bool{
+		"manifest": true, "manifest-concurrency": true,
+		"source-group": true, "source-project": true, "source-project-id": true, "target-group": true,
+	}
+	var out []string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if skip[f.Name] {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range sv.GetSlice() {
+				out = append(out, fmt.Sprintf("--%s=%s", f.Name, v))
+			}
+			return
+		}
+		out = append(out, fmt.Sprintf("--%s=%s", f.Name, f.Value.String()))
+	})
+	return out
+}
+
+// runForkManifest 以 --manifest-concurrency 指定的并发度，将 manifest 中的每个条目作为独立的
+// 子进程 (重新调用当前可执行文件的 'fork' 子命令) 执行，从而在不改动单次派生逻辑 (含大量 log.Fatal)
+// 的前提下，让一个条目的失败不影响其余条目的执行，并在结束后打印逐条目的成功/失败汇总。
+func runForkManifest(cmd *cobra.Command, manifestPath string, concurrency int) {
+	entries, err := loadForkManifest(cmd.Context(), manifestPath)
+	if err != nil {
+		logFatalf("❌ %v\n", err)
+	}
+	if len(entries) == 0 {
+		logFatal("❌ manifest 文件中没有任何条目。")
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		logFatalf("❌ 无法定位当前可执行文件用于批量派生子调用: %v\n", err)
+	}
+	passthrough := passthroughForkFlags(cmd)
+
+	log.Printf("🚀 正在从 manifest '%s' 批量派生 %d 个条目 (并发度: %d)...\n", manifestPath, len(entries), concurrency)
+
+	results := make([]forkManifestResult, len(entries))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, entry := range entries {
+		if entry.SourceGroup == "" || entry.SourceProject == "" || entry.TargetGroup == "" {
+			results[i] = forkManifestResult{Entry: entry, Err: fmt.Errorf("条目缺少 source-group/source-project/target-group 中的一项")}
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, entry forkManifestEntry) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			args := append([]string{"fork",
+				"--source-group", entry.SourceGroup,
+				"--source-project", entry.SourceProject,
+				"--target-group", entry.TargetGroup,
+			}, passthrough...)
+			prefix := fmt.Sprintf("%s/%s", entry.SourceGroup, entry.SourceProject)
+			output, err := runPrefixedSubcommand(exePath, args, prefix)
+			results[i] = forkManifestResult{Entry: entry, Success: err == nil, Output: output, Err: err}
+		}(i, entry)
+	}
+	wg.Wait()
+
+	succeeded, failed := 0, 0
+	log.Println("\n📦 批量派生结果汇总:")
+	for _, r := range results {
+		if r.Err == nil {
+			succeeded++
+			log.Printf("  ✅ %s/%s -> %s\n", r.Entry.SourceGroup, r.Entry.SourceProject, r.Entry.TargetGroup)
+			continue
+		}
+		failed++
+		log.Printf("  ❌ %s/%s -> %s: %v\n", r.Entry.SourceGroup, r.Entry.SourceProject, r.Entry.TargetGroup, r.Err)
+		if verbose && r.Output != "" {
+			log.Printf("     子进程输出:\n%s\n", r.Output)
+		}
+	}
+	log.Printf("\n🎉 批量派生完成，共 %d 个条目，成功 %d 个，失败 %d 个。\n", len(entries), succeeded, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
 // forkCmd 定义了 'fork' 子命令
 var forkCmd = &cobra.Command{
 	Use:   "fork",
 	Short: "将一个 GitLab 项目派生到另一个组",
 	Long: `此命令将指定的源项目从其当前组派生到目标组。
-需要两个 GitLab 个人访问令牌：一个用于读取源项目，一个用于在目标组创建项目。`,
+源/目标令牌通过 Kubernetes Secret 自动解析，无需在命令行传入。`,
+	Example: `  gitlab-fork-cli fork --source-group my-dev --source-project my-app --target-group my-prod
+  gitlab-fork-cli fork --source-group my-dev --source-project my-app --target-group my-prod --on-name-conflict=suffix
+  gitlab-fork-cli fork --source-group my-dev --source-project my-app --target-group my-prod --setup-environments --protect-production-environment
+  gitlab-fork-cli fork --source-group my-dev --source-project my-app --target-group my-prod --dry-run
+  gitlab-fork-cli fork --source-group my-dev --source-project my-app --target-group my-prod --wait --wait-timeout 10m
+  gitlab-fork-cli fork --source-group my-dev --source-project-id 4821 --target-group my-prod
+  gitlab-fork-cli fork --manifest forks.yaml --manifest-concurrency 8`,
 	Run: func(cmd *cobra.Command, args []string) {
-		// 1. Check required command-line arguments
-		if sourceGroup == "" || sourceProject == "" || targetGroup == "" || baseURL == "" {
-			log.Fatal("❌ 错误: 缺少必要的命令行参数。请使用 --help 查看用法。")
+		// 0. --manifest: 批量派生模式，跳过下方单次派生的必填参数校验，转而逐条目执行
+		if forkManifestPath != "" {
+			runForkManifest(cmd, forkManifestPath, manifestConcurrency)
+			return
 		}
 
-		// Get Kubernetes config once, for all K8s operations
-		log.Println("ℹ️ 正在获取 Kubernetes 配置...")
-		kubeRestConfig, err := k8sutil.GetKubeConfig()
-		if err != nil {
-			log.Fatalf("❌ 无法获取 Kubernetes 配置，无法检查命名空间或获取 Secret。错误: %v\n", err)
+		// 1. Check required command-line arguments
+		if baseURL == "" && sourceBaseURLFlag == "" && targetBaseURLFlag == "" {
+			logFatal("❌ 错误: 缺少必要的命令行参数。请使用 --help 查看用法。")
 		}
-
-		// 2. Check if sourceGroup (as Namespace) exists
-		log.Printf("ℹ️ 正在检查源组 (Kubernetes 命名空间) '%s' 是否存在...\n", sourceGroup)
-		sourceNsExists, err := k8sutil.CheckK8sNamespaceExists(kubeRestConfig, sourceGroup)
-		if err != nil {
-			log.Fatalf("❌ 检查源组命名空间失败。源组: %s, 错误: %v\n", sourceGroup, err)
+		sourceBaseURL := sourceBaseURLFlag
+		if sourceBaseURL == "" {
+			sourceBaseURL = baseURL
+		}
+		targetBaseURL := targetBaseURLFlag
+		if targetBaseURL == "" {
+			targetBaseURL = baseURL
+		}
+		// 源/目标实例地址不同时，GitLab 的 ForkProject API 无法跨实例调用，改为
+		// "在目标实例创建空项目 + 镜像克隆/推送源项目" 的方式实现，见下方步骤 10。
+		crossInstance := sourceBaseURL != targetBaseURL
+		if crossInstance {
+			log.Printf("ℹ️ 检测到源实例 (%s) 与目标实例 (%s) 不同，将使用跨实例镜像派生流程。\n", sourceBaseURL, targetBaseURL)
+		}
+		if sourceGroup == "" || targetGroup == "" {
+			logFatal("❌ 错误: 必须提供 --source-group 和 --target-group，或改用 --manifest 批量派生。")
 		}
-		if !sourceNsExists {
-			log.Fatalf("❌ 源组对应的 Kubernetes 命名空间 '%s' 不存在。请确认该命名空间已被纳管。\n", sourceGroup)
+		if sourceProject == "" && sourceProjectIDFlag == 0 {
+			logFatal("❌ 错误: 必须提供 --source-project 或 --source-project-id 中的一个。")
+		}
+		if noK8s {
+			if devTokenOverride == "" || prodTokenOverride == "" {
+				logFatal("❌ 错误: --no-k8s 模式下必须同时提供 --dev-token 和 --prod-token，无法再从 k8s Secret 自动解析令牌。")
+			}
+			if useAdminTokenPolicy != "never" {
+				logFatal("❌ 错误: --no-k8s 模式下 --use-admin-token 必须为 'never'，管理员令牌同样依赖 k8s Secret 解析。")
+			}
 		}
 
-		// 3. Check if targetGroup (as Namespace) exists
-		log.Printf("ℹ️ 正在检查目标组 (Kubernetes 命名空间) '%s' 是否存在...\n", targetGroup)
-		targetNsExists, err := k8sutil.CheckK8sNamespaceExists(kubeRestConfig, targetGroup)
-		if err != nil {
-			log.Fatalf("❌ 检查目标组命名空间失败。目标组: %s, 错误: %v\n", targetGroup, err)
+		warnings := pkg.NewWarningCollector()
+		warnings.SetStrict(strictMode)
+		warnings.SetCorrelationID(correlationID)
+		defer warnings.PrintSummary()
+		if insecureSkip {
+			if err := warnings.Add("insecure-tls", "已通过 --insecure 关闭 TLS 证书校验"); err != nil {
+				logFatalf("❌ %v", err)
+			}
 		}
-		if !targetNsExists {
-			log.Fatalf("❌ 目标组对应的 Kubernetes 命名空间 '%s' 不存在。请确认该命名空间已被纳管。\n", targetGroup)
+
+		ctx := cmd.Context()
+
+		timeline := pkg.NewTimeline()
+		if verbose {
+			defer timeline.PrintSummary()
 		}
+		timeline.StartPhase("resolve")
 
-		// 4. Get devToken from Kubernetes Secret (sourceGroup as Namespace)
-		log.Printf("ℹ️ 正在从 Kubernetes Secret 获取开发令牌...命名空间: %s, Secret名称: %s\n",
-			sourceGroup, GitlabSecretName)
-		devToken, err := k8sutil.GetSecretValue(kubeRestConfig, sourceGroup, GitlabSecretName, GitlabTokenKey)
-		if err != nil {
-			log.Fatalf("❌ 无法获取开发令牌。请确认输入的 source-group (%s) 对应的 Secret 存在且可访问。错误: %v\n",
-				sourceGroup, err)
+		// --no-k8s: 完全跳过 Kubernetes 配置获取、命名空间存在性检查与 Secret 令牌解析，
+		// 令牌改为强制要求已通过 --dev-token/--prod-token 直接提供 (已在上方校验)
+		var kubeRestConfig *rest.Config
+		if !noK8s {
+			// Get Kubernetes config once, for all K8s operations
+			log.Println("ℹ️ 正在获取 Kubernetes 配置...")
+			var err error
+			kubeRestConfig, err = k8sutil.GetKubeConfig()
+			if err != nil {
+				logFatalf("❌ 无法获取 Kubernetes 配置，无法检查命名空间或获取 Secret。错误: %v\n", err)
+			}
+
+			// 2. Check if sourceGroup (as Namespace) exists
+			log.Printf("ℹ️ 正在检查源组 (Kubernetes 命名空间) '%s' 是否存在...\n", sourceGroup)
+			sourceNsExists, err := k8sutil.CheckK8sNamespaceExists(ctx, kubeRestConfig, sourceGroup)
+			if err != nil {
+				logFatalf("❌ 检查源组命名空间失败。源组: %s, 错误: %v\n", sourceGroup, err)
+			}
+			if !sourceNsExists {
+				logFatalf("❌ 源组对应的 Kubernetes 命名空间 '%s' 不存在。请确认该命名空间已被纳管。\n", sourceGroup)
+			}
+
+			// 3. Check if targetGroup (as Namespace) exists
+			log.Printf("ℹ️ 正在检查目标组 (Kubernetes 命名空间) '%s' 是否存在...\n", targetGroup)
+			targetNsExists, err := k8sutil.CheckK8sNamespaceExists(ctx, kubeRestConfig, targetGroup)
+			if err != nil {
+				logFatalf("❌ 检查目标组命名空间失败。目标组: %s, 错误: %v\n", targetGroup, err)
+			}
+			if !targetNsExists {
+				logFatalf("❌ 目标组对应的 Kubernetes 命名空间 '%s' 不存在。请确认该命名空间已被纳管。\n", targetGroup)
+			}
+		} else {
+			log.Println("ℹ️ --no-k8s 已启用，跳过 Kubernetes 配置获取与命名空间存在性检查。")
+		}
+
+		// 4. Get devToken from Kubernetes Secret (sourceGroup as Namespace)，--no-k8s 时直接使用 --dev-token
+		var devToken string
+		if noK8s {
+			devToken = devTokenOverride
+			log.Println("ℹ️ --no-k8s 已启用，直接使用 --dev-token 提供的开发令牌。")
+		} else {
+			log.Printf("ℹ️ 正在从 Kubernetes Secret 获取开发令牌...命名空间: %s, Secret名称: %s\n",
+				sourceGroup, GitlabSecretName)
+			var err error
+			devToken, err = k8sutil.GetSecretValue(ctx, kubeRestConfig, sourceGroup, GitlabSecretName, GitlabTokenKey)
+			if err != nil {
+				logFatalf("❌ 无法获取开发令牌。请确认输入的 source-group (%s) 对应的 Secret 存在且可访问。错误: %v\n",
+					sourceGroup, err)
+			}
+			log.Println("✅ 成功获取开发令牌。")
+			if devTokenOverride != "" {
+				log.Println("⚠️ 检测到已废弃标志 --dev-token，将覆盖自动获取的开发令牌。请尽快迁移到基于 k8s Secret 的令牌解析。")
+				devToken = devTokenOverride
+			}
 		}
-		log.Println("✅ 成功获取开发令牌。")
 
 		// 5. Create devGit client to query source project
-		log.Printf("ℹ️ 正在使用开发令牌创建 GitLab 客户端...Base URL: %s\n", baseURL)
-		devGit, err := newGitLabClient(devToken, baseURL, insecureSkip)
+		log.Printf("ℹ️ 正在使用开发令牌创建 GitLab 客户端...Base URL: %s\n", sourceBaseURL)
+		devGit, err := newGitLabClient(devToken, sourceBaseURL, insecureSkip, caCertFile)
 		if err != nil {
-			log.Fatalf("❌ 创建 GitLab 开发客户端失败: %v\n", err)
+			logFatalf("❌ 创建 GitLab 开发客户端失败: %v\n", err)
 		}
 
-		// 6. Find source project ID
-		log.Printf("ℹ️ 正在查找源项目 '%s' 是否存在于 GitLab 组 '%s'...\n", sourceProject, sourceGroup)
-		sourceProjectID, err := findProjectInGroup(devGit, sourceGroup, sourceProject)
-		if err != nil {
-			log.Fatalf("❌ 源项目在 GitLab 组 '%s' 中未找到或查询失败。请确认项目名称和权限。错误: %v\n",
-				sourceGroup, err)
+		// 6. 确定源项目 ID：--source-project-id 精确指定优先；其次 --source-project 若形如
+		// "group/subgroup/project" 视为完整路径直接解析；否则退回到在 --source-group 下按名称搜索，
+		// 组内存在重名项目时后者可能产生歧义。
+		var sourceProjectID int
+		switch {
+		case sourceProjectIDFlag != 0:
+			log.Printf("ℹ️ 正在通过 --source-project-id=%d 查找源项目...\n", sourceProjectIDFlag)
+			project, _, err := devGit.Projects.GetProject(sourceProjectIDFlag, nil)
+			if err != nil {
+				logFatalf("❌ 未找到 ID 为 %d 的源项目: %v\n", sourceProjectIDFlag, err)
+			}
+			sourceProjectID = project.ID
+			sourceProject = project.Name
+			log.Printf("✅ 已通过 ID 找到源项目 '%s' (%s)。\n", project.Name, project.PathWithNamespace)
+		case strings.Contains(sourceProject, "/"):
+			log.Printf("ℹ️ --source-project 包含 '/'，视为完整路径直接查找: %s\n", sourceProject)
+			project, _, err := devGit.Projects.GetProject(sourceProject, nil)
+			if err != nil {
+				logFatalf("❌ 未找到路径为 '%s' 的源项目: %v\n", sourceProject, err)
+			}
+			sourceProjectID = project.ID
+			sourceProject = project.Name
+			log.Printf("✅ 已通过完整路径找到源项目 '%s' (%s)。\n", project.Name, project.PathWithNamespace)
+		default:
+			log.Printf("ℹ️ 正在查找源项目 '%s' 是否存在于 GitLab 组 '%s'...\n", sourceProject, sourceGroup)
+			id, err := findProjectInGroup(devGit, sourceGroup, sourceProject, groupEnumFilter{excludeSubgroups: excludeSubgroupPatterns, excludeProjects: excludeProjectPatterns, includeArchived: includeArchived, topics: topicFilter})
+			if err != nil {
+				logFatalf("❌ 源项目在 GitLab 组 '%s' 中未找到或查询失败。请确认项目名称和权限。错误: %v\n",
+					sourceGroup, err)
+			}
+			sourceProjectID = id
+			log.Printf("✅ 源项目 '%s' 已在 GitLab 组 '%s' 中找到。ID: %d\n",
+				sourceProject, sourceGroup, sourceProjectID)
 		}
-		log.Printf("✅ 源项目 '%s' 已在 GitLab 组 '%s' 中找到。ID: %d\n",
-			sourceProject, sourceGroup, sourceProjectID)
 
-		// 7. Get prodToken from Kubernetes Secret (targetGroup as Namespace)
-		log.Printf("ℹ️ 正在从 Kubernetes Secret 获取生产令牌...命名空间: %s, Secret名称: %s\n",
-			targetGroup, GitlabSecretName)
-		prodToken, err := k8sutil.GetSecretValue(kubeRestConfig, targetGroup, GitlabSecretName, GitlabTokenKey)
+		sourceProjectDetails, _, err := devGit.Projects.GetProject(sourceProjectID, nil)
 		if err != nil {
-			log.Fatalf("❌ 无法获取生产令牌。请确认输入的 target-group (%s) 对应的 Secret 存在且可访问。错误: %v\n",
-				targetGroup, err)
+			logFatalf("❌ 获取源项目 '%s' 的详情失败: %v\n", sourceProject, err)
+		}
+
+		// 7. Get prodToken from Kubernetes Secret (targetGroup as Namespace)，--no-k8s 时直接使用 --prod-token
+		var prodToken string
+		if noK8s {
+			prodToken = prodTokenOverride
+			log.Println("ℹ️ --no-k8s 已启用，直接使用 --prod-token 提供的生产令牌。")
+		} else {
+			log.Printf("ℹ️ 正在从 Kubernetes Secret 获取生产令牌...命名空间: %s, Secret名称: %s\n",
+				targetGroup, GitlabSecretName)
+			var err error
+			prodToken, err = k8sutil.GetSecretValue(ctx, kubeRestConfig, targetGroup, GitlabSecretName, GitlabTokenKey)
+			if err != nil {
+				logFatalf("❌ 无法获取生产令牌。请确认输入的 target-group (%s) 对应的 Secret 存在且可访问。错误: %v\n",
+					targetGroup, err)
+			}
+			log.Println("✅ 成功获取生产令牌。")
+			if prodTokenOverride != "" {
+				log.Println("⚠️ 检测到已废弃标志 --prod-token，将覆盖自动获取的生产令牌。请尽快迁移到基于 k8s Secret 的令牌解析。")
+				prodToken = prodTokenOverride
+			}
 		}
-		log.Println("✅ 成功获取生产令牌。")
 
 		// 8. Create prodGit client to perform fork operation in target group
-		log.Printf("ℹ️ 正在使用生产令牌创建 GitLab 客户端...Base URL: %s\n", baseURL)
-		prodGit, err := newGitLabClient(prodToken, baseURL, insecureSkip)
+		log.Printf("ℹ️ 正在使用生产令牌创建 GitLab 客户端...Base URL: %s\n", targetBaseURL)
+		prodGit, err := newGitLabClient(prodToken, targetBaseURL, insecureSkip, caCertFile)
 		if err != nil {
-			log.Fatalf("❌ 创建 GitLab 生产客户端失败: %v\n", err)
+			logFatalf("❌ 创建 GitLab 生产客户端失败: %v\n", err)
+		}
+
+		// 8.4 --ensure-target-group: 目标子组不存在时自动创建，避免下一步枚举目标组项目时收到 404
+		// (dry-run/read-only 时只在下方 9.6 打印意图，不做任何写入)
+		if ensureTargetGroup && !dryRun && !readOnly {
+			if err := ensureTargetGroupExists(prodGit, getModelGroupByNs(targetGroup), ensureTargetGroupVisibility, ensureTargetGroupDescription); err != nil {
+				logFatalf("❌ %v\n", err)
+			}
+		}
+
+		// 8.5 确定目标项目名：显式指定 --target-name 时直接使用；否则按 --name-template 生成，
+		// 生成/指定的名称都必须符合 --name-pattern 约束的生产命名规范
+		desiredProjectName := sourceProject
+		if targetName != "" {
+			desiredProjectName = targetName
+		} else if nameTemplate != "" {
+			desiredProjectName = renderNameTemplate(nameTemplate, sourceProject, targetGroup)
+		}
+		if err := validateProjectName(desiredProjectName, namePattern); err != nil {
+			logFatalf("❌ %v\n", err)
+		}
+
+		// 8.6 幂等重试：若提供了 --idempotency-key 且与目标命名空间上次记录的一致，
+		// 说明这是同一次调度触发的重试，直接返回上次的派生结果，避免重复派生。必须先于下方的
+		// 同名冲突预检执行——首次派生成功后名称冲突预检必然会命中同名项目，若幂等键检查放在
+		// 预检之后，默认的 --if-exists=fail/--on-name-conflict=error 会让预检直接 log.Fatalf 退出，
+		// 幂等短路分支永远无法触达，起不到"重试返回原结果"的保护作用。
+		// --no-k8s 模式下幂等标记依赖的命名空间 annotation 无法读写，仅记录警告并跳过。
+		if idempotencyKey != "" && noK8s {
+			if warnErr := warnings.Add("idempotency-unavailable", "--no-k8s 已启用，无法读写命名空间 annotation，--idempotency-key 本次不生效"); warnErr != nil {
+				logFatalf("❌ %v", warnErr)
+			}
+		}
+		if idempotencyKey != "" && !noK8s {
+			timeline.StartPhase("idempotency-check")
+			lastKey, ok, err := k8sutil.GetNamespaceAnnotation(ctx, kubeRestConfig, targetGroup, idempotencyKeyAnnotation)
+			if err != nil {
+				logFatalf("❌ 检查目标命名空间的幂等标记失败: %v\n", err)
+			}
+			if ok && lastKey == idempotencyKey {
+				resultPath, resultOk, err := k8sutil.GetNamespaceAnnotation(ctx, kubeRestConfig, targetGroup, idempotencyResultAnnotation)
+				if err != nil {
+					logFatalf("❌ 读取目标命名空间上记录的派生结果失败: %v\n", err)
+				}
+				if resultOk {
+					log.Printf("ℹ️ 检测到幂等键 '%s' 与上次记录一致，跳过重复派生，直接返回上次的派生结果 '%s'。\n", idempotencyKey, resultPath)
+					existingResult, _, err := prodGit.Projects.GetProject(resultPath, nil)
+					if err != nil {
+						logFatalf("❌ 幂等键匹配，但无法获取上次派生结果 '%s' 的项目信息: %v\n", resultPath, err)
+					}
+					log.Println("\n✅ 幂等重试：返回原有派生结果，未执行新的派生操作。")
+					log.Printf("  ID: %d\n", existingResult.ID)
+					log.Printf("  名称: %s\n", existingResult.Name)
+					log.Printf("  带命名空间的全名: %s\n", existingResult.PathWithNamespace)
+					log.Printf("  Web URL: %s\n", existingResult.WebURL)
+					return
+				}
+			}
 		}
 
 		// 9. Check if a project with the same name already exists in the target group
-		log.Printf("ℹ️ 正在检查目标组 '%s' 中是否已存在同名项目 '%s'...\n", targetGroup, sourceProject)
-		existingProjectID, err := findProjectInGroup(prodGit, getModelGroupByNs(targetGroup), sourceProject)
+		timeline.StartPhase("preflight")
+		log.Printf("ℹ️ 正在检查目标组 '%s' 中是否已存在同名项目 '%s'...\n", targetGroup, desiredProjectName)
+		// 存在性检查需要看到归档项目，避免误判目标组中无同名项目
+		existingProjectID, err := findProjectInGroup(prodGit, getModelGroupByNs(targetGroup), desiredProjectName, groupEnumFilter{includeArchived: true})
+		targetProjectName := desiredProjectName
 		if err == nil {
-			log.Fatalf("❌ 目标组 '%s' 中已存在同名项目 '%s' (ID: %d)。请手动处理或更改目标项目名称。\n",
-				targetGroup, sourceProject, existingProjectID)
+			// --if-exists=skip/reuse 在检测到同名项目时提前退出 (exit 0)，使工具在带重试语义的
+			// 流水线中可以安全地重复调用；--if-exists=fail (默认) 保持与此前完全一致的行为，
+			// 即仍由 --on-name-conflict 决定报错退出还是追加后缀派生为并行版本。
+			switch ifExistsPolicy {
+			case "skip":
+				log.Printf("ℹ️ 目标组 '%s' 中已存在同名项目 '%s' (ID: %d)，--if-exists=skip 已启用，跳过本次派生。\n",
+					targetGroup, desiredProjectName, existingProjectID)
+				return
+			case "reuse":
+				existingProject, _, err := prodGit.Projects.GetProject(existingProjectID, nil, gitlab.WithContext(ctx))
+				if err != nil {
+					logFatalf("❌ --if-exists=reuse: 获取已存在项目 (ID: %d) 详情失败: %v\n", existingProjectID, err)
+				}
+				log.Printf("ℹ️ 目标组 '%s' 中已存在同名项目 '%s' (ID: %d)，--if-exists=reuse 已启用，直接复用现有项目作为本次结果。\n",
+					targetGroup, desiredProjectName, existingProjectID)
+				log.Println("\n✅ 复用已存在项目:")
+				log.Printf("  ID: %d\n", existingProject.ID)
+				log.Printf("  名称: %s\n", existingProject.Name)
+				log.Printf("  带命名空间的全名: %s\n", existingProject.PathWithNamespace)
+				log.Printf("  Web URL: %s\n", existingProject.WebURL)
+				return
+			case "fail", "":
+				// 落入下方与此前完全一致的处理逻辑
+			default:
+				logFatalf("❌ 未知的 --if-exists 取值 '%s'，可选值: skip、fail (默认)、reuse。\n", ifExistsPolicy)
+			}
+			if onNameConflict != "suffix" {
+				logFatalf("❌ 目标组 '%s' 中已存在同名项目 '%s' (ID: %d)。请手动处理，或使用 --on-name-conflict=suffix 派生为并行的 A/B 版本。\n",
+					targetGroup, desiredProjectName, existingProjectID)
+			}
+			log.Printf("ℹ️ 目标组 '%s' 中已存在同名项目 '%s' (ID: %d)，--on-name-conflict=suffix 已启用，正在寻找可用的后缀名称...\n",
+				targetGroup, desiredProjectName, existingProjectID)
+			targetProjectName, err = resolveNameConflictBySuffix(prodGit, getModelGroupByNs(targetGroup), desiredProjectName)
+			if err != nil {
+				logFatalf("❌ 无法为项目 '%s' 找到可用的后缀名称: %v\n", desiredProjectName, err)
+			}
+			if err := validateProjectName(targetProjectName, namePattern); err != nil {
+				logFatalf("❌ 追加后缀后的名称不再符合命名规范: %v\n", err)
+			}
+			log.Printf("✅ 将使用 '%s' 作为本次派生的项目名/路径，实现与已有项目并行部署。\n", targetProjectName)
+		} else if !strings.Contains(err.Error(), "未找到项目") {
+			// If the error is "project not found", it's expected and we can proceed.
+			// Any other error means the check itself failed, and we should exit.
+			logFatalf("❌ 检查目标组是否存在同名项目失败。目标组: %s, 项目: %s, 错误: %v\n",
+				targetGroup, desiredProjectName, err)
+		} else {
+			log.Printf("✅ 目标组 '%s' 中未发现同名项目 '%s'，可以继续派生。\n", targetGroup, desiredProjectName)
+		}
+
+		// 9.6 --dry-run/--read-only: 所有校验均已通过，打印计划执行的操作后直接退出，不做任何写入
+		if dryRun || readOnly {
+			if readOnly {
+				log.Println("\n🔒 --read-only 已启用，以下为校验通过后计划执行的派生操作，未调用 GitLab API 做任何写入：")
+			} else {
+				log.Println("\nℹ️ --dry-run 已启用，以下为校验通过后计划执行的派生操作，未调用 GitLab API 做任何写入：")
+			}
+			log.Printf("  源项目: %s (ID: %d)，来自组 '%s'\n", sourceProject, sourceProjectID, sourceGroup)
+			log.Printf("  目标组: %s\n", getModelGroupByNs(targetGroup))
+			if ensureTargetGroup {
+				log.Printf("  若目标组不存在将自动创建 (可见性: %s)\n", ensureTargetGroupVisibility)
+			}
+			log.Printf("  目标项目名/路径: %s\n", targetProjectName)
+			if targetProjectDisplayName != "" {
+				log.Printf("  将单独覆盖显示名为 '%s' (--target-project-name)\n", targetProjectDisplayName)
+			}
+			if targetPath != "" {
+				log.Printf("  将单独覆盖路径为 '%s' (--target-path)\n", targetPath)
+			}
+			log.Printf("  管理员令牌使用策略 (--use-admin-token): %s\n", useAdminTokenPolicy)
+			if crossInstance {
+				log.Printf("  跨实例派生: 源实例 '%s' -> 目标实例 '%s'，将改用创建空项目 + 镜像克隆/推送实现\n", sourceBaseURL, targetBaseURL)
+			}
+			dryRunDescription := forkDescription
+			if dryRunDescription == "" {
+				dryRunDescription = renderDescriptionTemplate(descriptionTemplate, sourceProjectDetails.PathWithNamespace, promotedBy, ticketLabel, time.Now().Format("2006-01-02"))
+			}
+			log.Printf("  将设置的描述: %s\n", dryRunDescription)
+			if len(forkTopics) > 0 {
+				renderedTopics := make([]string, len(forkTopics))
+				for i, t := range forkTopics {
+					renderedTopics[i] = renderNameTemplate(t, sourceProject, targetGroup)
+				}
+				log.Printf("  将设置的 topics: %v\n", renderedTopics)
+			}
+			if targetVisibility != "" {
+				log.Printf("  将设置的可见性: %s (--target-visibility)\n", targetVisibility)
+			}
+			if breakForkRelationship {
+				log.Println("  将解除新项目与源项目的派生关系 (--break-fork-relationship)")
+			}
+			if provisionBadges {
+				log.Println("  将创建 'Serving status'/'Source project' 徽章 (--provision-badges)")
+			}
+			if setupEnvironments {
+				log.Println("  将创建 staging/production 环境 (--setup-environments)")
+			}
+			if provisionCIVariables {
+				log.Println("  将创建 PROMOTION_SOURCE_PROJECT/PROMOTION_SOURCE_SHA/PROMOTION_TAG CI 变量 (--provision-ci-variables)")
+			}
+			if settingsProfilePath != "" {
+				log.Printf("  将按 --settings-profile '%s' 从源项目复制选定类别的设置\n", settingsProfilePath)
+			}
+			if copyCIVariables {
+				log.Println("  将复制源项目的 CI/CD 变量 (--copy-ci-variables)")
+			}
+			if copyProtections {
+				log.Println("  将复制源项目的受保护分支与受保护标签规则 (--copy-protections)")
+			}
+			if copyWebhooks {
+				log.Println("  将复制源项目的 webhooks (--copy-webhooks)")
+			}
+			if copyDeployKeys {
+				log.Println("  将复制源项目的部署密钥 (--copy-deploy-keys)")
+			}
+			if copyMembers {
+				log.Println("  将复制源项目的成员与共享群组 (--copy-members)")
+			}
+			log.Println("\n✅ --dry-run 检查完成，未执行实际派生。")
+			return
 		}
-		// If the error is "project not found", it's expected and we can proceed.
-		// Any other error means the check itself failed, and we should exit.
-		if err != nil && !strings.Contains(err.Error(), "未找到项目") {
-			log.Fatalf("❌ 检查目标组是否存在同名项目失败。目标组: %s, 项目: %s, 错误: %v\n",
-				targetGroup, sourceProject, err)
+
+		// 10. Perform the fork operation. --use-admin-token 决定是否、何时使用 kubeflow 命名空间下的
+		// 管理员令牌代替目标组自身的生产令牌，避免在使用者未察觉的情况下静默越权操作目标组。
+		fetchAdminGit := func(reason string) *gitlab.Client {
+			log.Printf("⚠️ %s，正在改用 kubeflow 管理员令牌...\n", reason)
+			adminToken, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, "kubeflow", GitlabSecretName, GitlabTokenKey)
+			if err != nil {
+				logFatalf("❌ 无法获取管理员令牌: %v\n", err)
+			}
+			admindGit, err := newGitLabClient(adminToken, targetBaseURL, insecureSkip, caCertFile)
+			if err != nil {
+				logFatalf("❌ 创建 GitLab 管理员客户端失败: %v\n", err)
+			}
+			return admindGit
 		}
-		log.Printf("✅ 目标组 '%s' 中未发现同名项目 '%s'，可以继续派生。\n", targetGroup, sourceProject)
 
-		// 10. Perform the fork operation
-		adminToken, err := k8sutil.GetSecretValue(kubeRestConfig, "kubeflow", GitlabSecretName, GitlabTokenKey)
-		if err != nil {
-			log.Fatalf("❌ 无法获取生产令牌。请确认输入的 target-group (%s) 对应的 Secret 存在且可访问。错误: %v\n",
-				"kubeflow", err)
+		var admindGit *gitlab.Client
+		switch useAdminTokenPolicy {
+		case "always":
+			admindGit = fetchAdminGit("--use-admin-token=always 已启用")
+		case "never", "fallback":
+			admindGit = prodGit
+		default:
+			logFatalf("❌ 无效的 --use-admin-token '%s'，可选值: never, fallback, always。\n", useAdminTokenPolicy)
 		}
 
-		log.Println("✅ 成功获取生产令牌。")
-		admindGit, err := newGitLabClient(adminToken, baseURL, insecureSkip)
-		if err != nil {
-			log.Fatalf("❌ 创建 GitLab 生产客户端失败: %v\n", err)
+		if provisionBadges && dashboardBaseURL == "" {
+			logFatal("❌ 使用 --provision-badges 时必须提供 --dashboard-base-url。")
 		}
 
+		timeline.StartPhase("fork")
 		log.Printf("🚀 正在将项目 '%s' (ID: %d) 派生到目标组 '%s'...\n",
 			sourceProject, sourceProjectID, targetGroup)
 
-		forkOptions := &gitlab.ForkProjectOptions{
-			Namespace: gitlab.Ptr(getModelGroupByNs(targetGroup)), // Ensure forking to the correct group
+		// 记录溯源信息的项目描述：--description 提供时完全覆盖，否则按 --description-template 渲染，
+		// 让查看生产项目的人无需跳转其他系统即可看到其来源、推广人、日期与关联工单
+		description := forkDescription
+		if description == "" {
+			description = renderDescriptionTemplate(descriptionTemplate, sourceProjectDetails.PathWithNamespace, promotedBy, ticketLabel, time.Now().Format("2006-01-02"))
+		}
+		renderedTopics := make([]string, len(forkTopics))
+		for i, t := range forkTopics {
+			renderedTopics[i] = renderNameTemplate(t, sourceProject, targetGroup)
 		}
 
-		// Use prodGit for the fork operation as it has the necessary permissions for the target group
-		newProject, resp, err := admindGit.Projects.ForkProject(sourceProjectID, forkOptions)
-		if err != nil {
-			if resp != nil {
-				log.Printf("派生项目请求返回错误状态码。源项目: %s, 目标组: %s, HTTP状态码: %d, 原始错误: %v\n",
-					sourceProject, targetGroup, resp.StatusCode, err)
-				switch resp.StatusCode {
-				case http.StatusNotFound:
-					log.Fatal("❌ 派生项目失败: 可能原因 - 目标组不存在，或源项目不存在。")
-				case http.StatusForbidden:
-					log.Fatal("❌ 派生项目失败: 生产令牌在目标组没有足够的派生权限。")
-				case http.StatusConflict:
-					log.Fatal("❌ 派生项目失败: 目标组中已存在同名项目。") // This should ideally be caught by the pre-check
-				default:
-					log.Fatalf("❌ 派生项目失败: %v\n", err)
+		var newProject *gitlab.Project
+
+		if crossInstance {
+			// 跨实例派生：GitLab 的 ForkProject API 无法跨实例调用，改为在目标实例上创建一个空项目，
+			// 再通过 pkg/gitops 镜像克隆源项目的默认分支与全部分支/标签并推送过去，等效地实现"派生"。
+			creatorGit := prodGit
+			if useAdminTokenPolicy == "always" {
+				creatorGit = admindGit
+			}
+			targetNamespacePath := getModelGroupByNs(targetGroup)
+			namespace, _, err := creatorGit.Namespaces.GetNamespace(targetNamespacePath, gitlab.WithContext(ctx))
+			if err != nil {
+				logFatalf("❌ 跨实例派生: 查询目标命名空间 '%s' 失败: %v\n", targetNamespacePath, err)
+			}
+			createProjectName := targetProjectName
+			if targetProjectDisplayName != "" {
+				createProjectName = targetProjectDisplayName
+			}
+			createProjectPath := targetProjectName
+			if targetPath != "" {
+				createProjectPath = targetPath
+			}
+			created, _, err := creatorGit.Projects.CreateProject(&gitlab.CreateProjectOptions{
+				Name:        gitlab.Ptr(createProjectName),
+				Path:        gitlab.Ptr(createProjectPath),
+				NamespaceID: gitlab.Ptr(namespace.ID),
+				Description: gitlab.Ptr(description),
+			}, gitlab.WithContext(ctx))
+			if err != nil {
+				logFatalf("❌ 跨实例派生: 在目标实例创建项目 '%s/%s' 失败: %v\n", targetNamespacePath, targetProjectName, err)
+			}
+			log.Printf("✅ 已在目标实例创建空项目 '%s' (ID: %d)，正在镜像克隆源项目内容...\n", created.PathWithNamespace, created.ID)
+
+			outputDir, err := os.MkdirTemp("", "gitlab-fork-cli-cross-fork-")
+			if err != nil {
+				logFatalf("❌ 创建临时目录失败: %v\n", err)
+			}
+			defer os.RemoveAll(outputDir)
+
+			mirrorOpts := pkg.GitOperationOptions{
+				FromRepoURL:     buildRepoURLFromProject(sourceBaseURL, sourceProjectDetails.PathWithNamespace),
+				FromRef:         sourceProjectDetails.DefaultBranch,
+				FromAuth:        &pkg.BasicAuthMethod{Username: "oauth2", Password: devToken},
+				ToRepoURL:       buildRepoURLFromProject(targetBaseURL, created.PathWithNamespace),
+				ToAuth:          &pkg.BasicAuthMethod{Username: "oauth2", Password: prodToken},
+				OutputDir:       outputDir,
+				ProgressWriter:  os.Stdout,
+				PushBranches:    true, // 镜像全部分支，而不仅仅是默认分支
+				Warnings:        warnings,
+				Timeline:        timeline,
+				InsecureSkipTLS: insecureSkip,
+				CACertFile:      caCertFile,
+			}
+			if err := pkg.PerformGitOperation(ctx, mirrorOpts); err != nil {
+				logFatalf("❌ 跨实例派生: 镜像克隆/推送失败 (目标项目 '%s' 已创建但内容为空，需要人工清理或重试): %v\n", created.PathWithNamespace, err)
+			}
+
+			if len(renderedTopics) > 0 {
+				if _, _, err := creatorGit.Projects.EditProject(created.ID, &gitlab.EditProjectOptions{Topics: &renderedTopics}, gitlab.WithContext(ctx)); err != nil {
+					if warnErr := warnings.Add("topics-provision-failed", "为项目 '%s' 设置 topics 失败: %v", created.PathWithNamespace, err); warnErr != nil {
+						logFatalf("❌ %v", warnErr)
+					}
+				}
+			}
+			if targetVisibility != "" {
+				if _, _, err := creatorGit.Projects.EditProject(created.ID, &gitlab.EditProjectOptions{Visibility: gitlab.Ptr(gitlab.VisibilityValue(targetVisibility))}, gitlab.WithContext(ctx)); err != nil {
+					if warnErr := warnings.Add("visibility-provision-failed", "为项目 '%s' 设置可见性失败: %v", created.PathWithNamespace, err); warnErr != nil {
+						logFatalf("❌ %v", warnErr)
+					}
+				}
+			}
+			if provisionBadges {
+				if err := pkg.ProvisionProjectBadges(ctx, creatorGit, created.ID, sourceProjectDetails.WebURL, dashboardBaseURL); err != nil {
+					if warnErr := warnings.Add("badge-provision-failed", "为项目 '%s' 创建徽章失败: %v", created.PathWithNamespace, err); warnErr != nil {
+						logFatalf("❌ %v", warnErr)
+					}
+				}
+			}
+			if setupEnvironments {
+				if err := pkg.ProvisionEnvironments(ctx, creatorGit, created.ID, protectProductionEnv); err != nil {
+					if warnErr := warnings.Add("environment-provision-failed", "为项目 '%s' 创建环境失败: %v", created.PathWithNamespace, err); warnErr != nil {
+						logFatalf("❌ %v", warnErr)
+					}
+				}
+			}
+
+			if waitForImport {
+				log.Println("ℹ️ 跨实例派生通过同步的镜像推送完成，无需异步导入，--wait 被忽略。")
+			}
+
+			newProject = created
+		} else {
+			// 派生本身、等待导入、设置 topics/徽章/环境这几步的实际 GitLab API 调用已下沉到
+			// pkg.Fork，此处只负责决定用哪个客户端发起请求、以及权限不足时是否要降级为管理员令牌重试。
+			doFork := func(client *gitlab.Client) (*pkg.ForkResult, error) {
+				return pkg.Fork(ctx, pkg.ForkRequest{
+					Client:                   client,
+					SourceProjectID:          sourceProjectID,
+					SourceProjectName:        sourceProject,
+					TargetGroupPath:          getModelGroupByNs(targetGroup),
+					TargetProjectName:        targetProjectName,
+					TargetProjectDisplayName: targetProjectDisplayName,
+					TargetProjectPath:        targetPath,
+					Description:              description,
+					Topics:                   renderedTopics,
+					Visibility:               gitlab.VisibilityValue(targetVisibility),
+					BreakForkRelationship:    breakForkRelationship,
+					ProvisionBadges:          provisionBadges,
+					DashboardBaseURL:         dashboardBaseURL,
+					SetupEnvironments:        setupEnvironments,
+					ProtectProductionEnv:     protectProductionEnv,
+					Wait:                     waitForImport,
+					WaitTimeout:              waitTimeout,
+					WaitPollPeriod:           waitPollPeriod,
+					Warnings:                 warnings,
+				})
+			}
+
+			result, err := doFork(admindGit)
+			if err != nil && errors.Is(err, pkg.ErrForkPermissionDenied) && useAdminTokenPolicy != "always" {
+				// 降级为管理员令牌 (或直接报错退出) 之前，先报告清楚生产令牌当前的实际角色与所需角色，
+				// 避免每次权限不足都只能靠管理员令牌"糊过去"而不知道该给谁修复权限。
+				reportGroupPermission(admindGit, getModelGroupByNs(targetGroup), requiredForkAccessLevel)
+				if useAdminTokenPolicy == "fallback" {
+					admindGit = fetchAdminGit("生产令牌在目标组权限不足 (403)，--use-admin-token=fallback 已启用")
+					result, err = doFork(admindGit)
+				}
+			}
+			switch {
+			case errors.Is(err, pkg.ErrForkSourceOrTargetNotFound):
+				logFatal("❌ 派生项目失败: 可能原因 - 目标组不存在，或源项目不存在。")
+			case errors.Is(err, pkg.ErrForkPermissionDenied):
+				if useAdminTokenPolicy == "never" {
+					logFatal("❌ 派生项目失败: 生产令牌在目标组没有足够的派生权限，且 --use-admin-token=never 禁止降级为管理员令牌。")
+				}
+				logFatal("❌ 派生项目失败: 生产令牌在目标组没有足够的派生权限。")
+			case errors.Is(err, pkg.ErrForkNameConflict):
+				logFatal("❌ 派生项目失败: 目标组中已存在同名项目。") // This should ideally be caught by the pre-check
+			case err != nil:
+				logFatalf("❌ %v\n", err)
+			}
+			newProject = result.Project
+		}
+
+		// 10.5 --provision-ci-variables: 在新项目上写入记录本次派生来源的 CI/CD 变量，
+		// 使其自身流水线无需调用外部系统即可获知来源项目、来源提交与推广标签
+		if provisionCIVariables {
+			sourceCommit, _, err := devGit.Commits.GetCommit(sourceProjectID, sourceProjectDetails.DefaultBranch, nil, gitlab.WithContext(ctx))
+			if err != nil {
+				if warnErr := warnings.Add("promotion-variables-provision-failed", "获取源项目 '%s' 的最新提交失败，跳过写入 CI 变量: %v", sourceProjectDetails.PathWithNamespace, err); warnErr != nil {
+					logFatalf("❌ %v", warnErr)
+				}
+			} else {
+				variablesClient := prodGit
+				if useAdminTokenPolicy == "always" {
+					variablesClient = admindGit
+				}
+				if err := pkg.ProvisionPromotionVariables(ctx, variablesClient, newProject.ID, sourceProjectDetails.PathWithNamespace, sourceCommit.ID, promotedTag); err != nil {
+					if warnErr := warnings.Add("promotion-variables-provision-failed", "为项目 '%s' 写入 CI 变量失败: %v", newProject.PathWithNamespace, err); warnErr != nil {
+						logFatalf("❌ %v", warnErr)
+					}
 				}
 			}
-			log.Fatalf("❌ 派生项目请求失败: %v\n", err)
 		}
 
-		if resp.StatusCode != http.StatusCreated {
-			log.Fatalf("❌ 派生项目失败，HTTP 状态码不是 201 Created，实际状态码: %d\n", resp.StatusCode)
+		// 10.6 --settings-profile: 按策略文件从源项目复制指定类别的设置到新派生项目
+		if settingsProfilePath != "" {
+			profile, err := loadSettingsProfile(ctx, settingsProfilePath)
+			if err != nil {
+				logFatalf("❌ %v\n", err)
+			}
+			settingsClient := prodGit
+			if useAdminTokenPolicy == "always" {
+				settingsClient = admindGit
+			}
+			if err := profile.Apply(ctx, settingsClient, sourceProjectID, newProject.ID, warnings); err != nil {
+				logFatalf("❌ %v", err)
+			}
+		}
+
+		// 10.7 --copy-ci-variables: 将源项目的 CI/CD 变量复制到新项目，与 --settings-profile 中的
+		// variables 类别等价，但更轻量、无需额外的策略文件，可选跳过受保护/masked 变量
+		if copyCIVariables {
+			ciVariablesClient := prodGit
+			if useAdminTokenPolicy == "always" {
+				ciVariablesClient = admindGit
+			}
+			if err := pkg.CopyProjectVariablesFiltered(ctx, ciVariablesClient, sourceProjectID, newProject.ID, copyCIVariablesSkipProtected, copyCIVariablesSkipMasked); err != nil {
+				if warnErr := warnings.Add("copy-ci-variables-failed", "复制 CI/CD 变量到项目 '%s' 失败: %v", newProject.PathWithNamespace, err); warnErr != nil {
+					logFatalf("❌ %v", warnErr)
+				}
+			}
+		}
+
+		// 10.8 --copy-protections: 将源项目的受保护分支/受保护标签规则复制到新项目，
+		// 与 --settings-profile 中的 protections 类别等价，但更轻量、无需额外的策略文件
+		if copyProtections {
+			protectionsClient := prodGit
+			if useAdminTokenPolicy == "always" {
+				protectionsClient = admindGit
+			}
+			if err := pkg.CopyProtectedBranches(ctx, protectionsClient, sourceProjectID, newProject.ID); err != nil {
+				if warnErr := warnings.Add("copy-protected-branches-failed", "复制受保护分支到项目 '%s' 失败: %v", newProject.PathWithNamespace, err); warnErr != nil {
+					logFatalf("❌ %v", warnErr)
+				}
+			}
+			if err := pkg.CopyProtectedTags(ctx, protectionsClient, sourceProjectID, newProject.ID); err != nil {
+				if warnErr := warnings.Add("copy-protected-tags-failed", "复制受保护标签到项目 '%s' 失败: %v", newProject.PathWithNamespace, err); warnErr != nil {
+					logFatalf("❌ %v", warnErr)
+				}
+			}
+		}
+
+		// 10.9 --copy-webhooks / --copy-deploy-keys: 将源项目的 webhooks、部署密钥复制到新项目，
+		// 与 --settings-profile 中的对应类别等价，但更轻量、无需额外的策略文件
+		if copyWebhooks {
+			webhookSecrets := map[string]string{}
+			if webhookSecretsFile != "" {
+				secrets, err := loadWebhookSecretsMapping(ctx, webhookSecretsFile)
+				if err != nil {
+					logFatalf("❌ %v\n", err)
+				}
+				webhookSecrets = secrets
+			}
+			webhooksClient := prodGit
+			if useAdminTokenPolicy == "always" {
+				webhooksClient = admindGit
+			}
+			if err := pkg.CopyWebhooksWithSecrets(ctx, webhooksClient, sourceProjectID, newProject.ID, webhookSecrets); err != nil {
+				if warnErr := warnings.Add("copy-webhooks-failed", "复制 webhooks 到项目 '%s' 失败: %v", newProject.PathWithNamespace, err); warnErr != nil {
+					logFatalf("❌ %v", warnErr)
+				}
+			}
+		}
+		if copyDeployKeys {
+			deployKeysClient := prodGit
+			if useAdminTokenPolicy == "always" {
+				deployKeysClient = admindGit
+			}
+			if err := pkg.CopyDeployKeys(ctx, deployKeysClient, sourceProjectID, newProject.ID); err != nil {
+				if warnErr := warnings.Add("copy-deploy-keys-failed", "复制部署密钥到项目 '%s' 失败: %v", newProject.PathWithNamespace, err); warnErr != nil {
+					logFatalf("❌ %v", warnErr)
+				}
+			}
+		}
+
+		// 10.10 --copy-members: 将源项目的直接成员与共享群组复制到新项目，与 --settings-profile
+		// 中的 members 类别等价，但更轻量、无需额外的策略文件，且支持 --min-access-level 过滤
+		if copyMembers {
+			minLevel := gitlab.NoPermissions
+			if copyMembersMinAccessLevel != "" {
+				level, ok := groupAccessLevelByName[strings.ToLower(copyMembersMinAccessLevel)]
+				if !ok {
+					logFatalf("❌ 无效的 --min-access-level '%s'，可选值: guest, reporter, developer, maintainer, owner。\n", copyMembersMinAccessLevel)
+				}
+				minLevel = level
+			}
+			membersClient := prodGit
+			if useAdminTokenPolicy == "always" {
+				membersClient = admindGit
+			}
+			if err := pkg.CopyMembersFiltered(ctx, membersClient, sourceProjectID, newProject.ID, minLevel); err != nil {
+				if warnErr := warnings.Add("copy-members-failed", "复制成员到项目 '%s' 失败: %v", newProject.PathWithNamespace, err); warnErr != nil {
+					logFatalf("❌ %v", warnErr)
+				}
+			}
+			if err := pkg.CopyGroupShares(ctx, membersClient, sourceProjectID, newProject.ID); err != nil {
+				if warnErr := warnings.Add("copy-group-shares-failed", "复制共享群组到项目 '%s' 失败: %v", newProject.PathWithNamespace, err); warnErr != nil {
+					logFatalf("❌ %v", warnErr)
+				}
+			}
 		}
 
 		// 11. Print information about the newly forked project
@@ -239,6 +1339,32 @@ var forkCmd = &cobra.Command{
 		} else {
 			log.Println("  派生自: (信息不可用或非派生项目)")
 		}
+		if len(forkTopics) > 0 {
+			log.Printf("✅ topics 已设置为: %v\n", renderedTopics)
+		}
+		if provisionBadges {
+			log.Println("✅ 徽章创建完成。")
+		}
+		if setupEnvironments {
+			log.Println("✅ 环境创建完成。")
+		}
+
+		// 14. 记录本次幂等键与派生结果，供同一调度触发的重试请求识别并跳过重复派生
+		if idempotencyKey != "" && !noK8s {
+			if err := k8sutil.SetNamespaceAnnotation(ctx, kubeRestConfig, targetGroup, idempotencyKeyAnnotation, idempotencyKey); err != nil {
+				log.Printf("⚠️ 记录幂等键失败，重试时可能无法识别为同一次调用: %v\n", err)
+			}
+			if err := k8sutil.SetNamespaceAnnotation(ctx, kubeRestConfig, targetGroup, idempotencyResultAnnotation, newProject.PathWithNamespace); err != nil {
+				log.Printf("⚠️ 记录幂等派生结果失败，重试时可能无法识别为同一次调用: %v\n", err)
+			}
+		}
+
+		// 14.5 记录触发本次派生的关联 ID，便于事后从命名空间反查是哪一次运行完成了该派生
+		if !noK8s {
+			if err := k8sutil.SetNamespaceAnnotation(ctx, kubeRestConfig, targetGroup, lastCorrelationIDAnnotation, correlationID); err != nil {
+				log.Printf("⚠️ 记录关联 ID 失败: %v\n", err)
+			}
+		}
 
 		log.Println("\n✅ 操作完成。")
 	},
@@ -247,15 +1373,72 @@ var forkCmd = &cobra.Command{
 func init() {
 	// 定义 fork 命令的本地标志
 	forkCmd.Flags().StringVarP(&sourceGroup, "source-group", "g", "", "项目开发所在的NS名称 (GitLab 组的名称)(必填)")
-	forkCmd.Flags().StringVarP(&sourceProject, "source-project", "p", "", "平台项目的名称 (必填)")
+	forkCmd.Flags().StringVarP(&sourceProject, "source-project", "p", "", "平台项目的名称，也可传入 'group/subgroup/project' 形式的完整路径以避免重名歧义 (与 --source-project-id 二选一，必填)")
+	forkCmd.Flags().IntVar(&sourceProjectIDFlag, "source-project-id", 0, "直接通过项目 ID 指定源项目，优先于 --source-project 按名称/路径查找，避免组内重名项目导致的歧义")
 	forkCmd.Flags().StringVarP(&targetGroup, "target-group", "t", "", "项目推理服务将要创建到的NS名称 (必填)")
-	//forkCmd.Flags().StringVarP(&devToken, "dev-token", "d", "", "用于读取源项目的 GitLab 个人访问令牌 (必填)")
-	//forkCmd.Flags().StringVarP(&prodToken, "prod-token", "r", "", "用于在目标组创建（派生）项目的 GitLab 个人访问令牌 (必填)")
-
-	// 标记这些标志为必填
-	forkCmd.MarkFlagRequired("source-group")
-	forkCmd.MarkFlagRequired("source-project")
-	forkCmd.MarkFlagRequired("target-group")
-	//forkCmd.MarkFlagRequired("dev-token")
-	//forkCmd.MarkFlagRequired("prod-token")
+	forkCmd.Flags().StringSliceVar(&excludeSubgroupPatterns, "exclude-subgroup", nil, "排除子组的 glob 模式，可重复指定，用于批量派生时跳过归档/沙箱子组")
+	forkCmd.Flags().StringSliceVar(&excludeProjectPatterns, "exclude-project", nil, "排除项目的 glob 模式，可重复指定，用于批量派生时跳过特定项目")
+	forkCmd.Flags().BoolVar(&includeArchived, "include-archived", false, "允许将已归档的项目作为派生源 (默认跳过归档项目，避免误将过期代码提升)")
+	forkCmd.Flags().StringSliceVar(&topicFilter, "topic", nil, "仅允许携带指定 topic 之一的项目作为派生源，可重复指定或用逗号分隔 (如 model,llm)")
+	forkCmd.Flags().BoolVar(&provisionBadges, "provision-badges", false, "派生成功后在新项目上创建 'Serving status' 与 'Source project' 徽章/链接，便于回溯监控面板与来源项目")
+	forkCmd.Flags().StringVar(&dashboardBaseURL, "dashboard-base-url", "", "平台监控面板的基础 URL，与 --provision-badges 搭配使用")
+	forkCmd.Flags().BoolVar(&setupEnvironments, "setup-environments", false, "派生成功后在新项目上创建 staging/production 环境，满足 CD 工具在首次流水线运行前的前置条件")
+	forkCmd.Flags().BoolVar(&protectProductionEnv, "protect-production-environment", false, "与 --setup-environments 搭配使用，将 production 环境设置为仅 Maintainer 可部署的受保护环境")
+	forkCmd.Flags().StringVar(&onNameConflict, "on-name-conflict", "error", "目标组中已存在同名项目时的处理策略：'error' (默认，报错退出) 或 'suffix' (追加 -2/-3... 后缀，实现同一模型的并行 A/B 派生)")
+	forkCmd.Flags().StringVar(&ifExistsPolicy, "if-exists", "fail", "目标组中已存在同名项目时是否直接以成功结束：'fail' (默认，交由 --on-name-conflict 决定报错还是追加后缀)、'skip' (打印现有项目信息后以 exit 0 跳过) 或 'reuse' (将现有项目信息作为本次结果打印后以 exit 0 结束)，用于使工具在带重试语义的流水线中可以安全地重复调用")
+	forkCmd.Flags().StringVar(&idempotencyKey, "idempotency-key", "", "幂等键：记录在目标命名空间上，若重试请求携带相同的键，则在同名冲突预检 (--if-exists/--on-name-conflict) 之前直接返回上次的派生结果而不重复派生 (用于对接至少一次语义的调度器)")
+	forkCmd.Flags().StringVar(&targetName, "target-name", "", "显式指定目标项目名/路径，省略时按 --name-template 生成")
+	forkCmd.Flags().StringVar(&nameTemplate, "name-template", "", "省略 --target-name 时用于生成目标项目名的模板，支持 {source}/{env} 占位符 (如 '{source}-{env}')")
+	forkCmd.Flags().StringVar(&namePattern, "name-pattern", "", "生产命名规范的正则表达式，目标项目名 (无论来自 --target-name 还是 --name-template) 必须与之匹配，否则拒绝派生")
+	forkCmd.Flags().StringVar(&targetProjectDisplayName, "target-project-name", "", "单独覆盖新项目的显示名 (GitLab 的 Name 字段)，省略时回退到 --target-name/--name-template 解析出的名称")
+	forkCmd.Flags().StringVar(&targetPath, "target-path", "", "单独覆盖新项目的路径 (URL slug，GitLab 的 Path 字段)，省略时回退到 --target-name/--name-template 解析出的名称；常用于新增环境后缀如 'iris-prod'")
+	forkCmd.Flags().StringSliceVar(&forkTopics, "topics", nil, "派生成功后为新项目打上的 topics，可重复指定或用逗号分隔，支持 {source}/{env} 占位符 (如 'promoted,source:{env}')")
+	forkCmd.Flags().StringVar(&targetVisibility, "target-visibility", "", "派生成功后单独设置新项目的可见性 ('private'、'internal' 或 'public')，覆盖 ForkProject 默认继承自源项目的可见性，用于将生产副本强制设为 private")
+	forkCmd.Flags().BoolVar(&breakForkRelationship, "break-fork-relationship", false, "派生成功后调用 DeleteProjectForkRelation 移除新项目与源项目的派生关系，满足生产副本需与上游代码库脱钩的合规要求 (对跨实例派生模式无意义，因为该模式本就不建立派生关系)")
+	forkCmd.Flags().BoolVar(&dryRun, "dry-run", false, "完成所有校验后只打印计划执行的派生操作，不实际调用 ForkProject，用于在流水线中安全预演")
+	forkCmd.Flags().StringVar(&forkDescription, "description", "", "显式指定新项目的描述，覆盖 --description-template 生成的内容")
+	forkCmd.Flags().StringVar(&descriptionTemplate, "description-template", defaultDescriptionTemplate, "省略 --description 时用于生成新项目描述的模板，支持 {source}/{promoted_by}/{date}/{ticket} 占位符")
+	forkCmd.Flags().StringVar(&promotedBy, "promoted-by", "", "本次派生的推广人，写入新项目描述中的 {promoted_by} 占位符")
+	forkCmd.Flags().StringVar(&ticketLabel, "ticket", "", "本次派生关联的工单号，写入新项目描述中的 {ticket} 占位符")
+	forkCmd.Flags().BoolVar(&waitForImport, "wait", false, "派生成功后轮询新项目的导入状态，直到导入完成再退出，避免调用方立即对尚未导入完成的仓库执行 git 操作")
+	forkCmd.Flags().DurationVar(&waitTimeout, "wait-timeout", 5*time.Minute, "与 --wait 搭配使用，等待导入完成的最长时长，超时报错退出")
+	forkCmd.Flags().DurationVar(&waitPollPeriod, "wait-poll-interval", 5*time.Second, "与 --wait 搭配使用，轮询导入状态的间隔")
+	forkCmd.Flags().StringVar(&useAdminTokenPolicy, "use-admin-token", "fallback", "kubeflow 管理员令牌的使用策略：'never' (禁止使用，权限不足报错退出)、'fallback' (仅当生产令牌权限不足时降级使用，默认)、'always' (始终使用)")
+	forkCmd.Flags().StringVar(&forkManifestPath, "manifest", "", "批量派生模式：从 YAML 来源读取一组 {source-group, source-project, target-group} 条目并逐个执行，与 --source-group/--source-project/--target-group 互斥；其余标志对每个条目统一生效。除本地文件路径外还支持 '-' (标准输入)、'http(s)://' URL、'configmap://<namespace>/<name>/<key>'，便于 GitOps 系统直接传入生成的 manifest")
+	forkCmd.Flags().IntVar(&manifestConcurrency, "manifest-concurrency", 4, "与 --manifest 搭配使用，批量派生的并发度")
+	// 已废弃标志的兼容层：新的令牌解析方式改为从 k8s Secret 自动获取，此处保留旧标志名 (仅在帮助文本中注明为
+	// 已废弃用法，不调用 MarkDeprecated，因为 --no-k8s 模式下仍必填)，避免依赖旧标志的现有自动化脚本直接报错退出
+	forkCmd.Flags().StringVar(&devTokenOverride, "dev-token", "", "用于读取源项目的 GitLab 个人访问令牌：在正常模式下临时覆盖自动从 k8s Secret 解析出的开发令牌 (已废弃用法)；在 --no-k8s 模式下为必填项，直接作为开发令牌使用")
+	forkCmd.Flags().StringVar(&prodTokenOverride, "prod-token", "", "用于在目标组创建（派生）项目的 GitLab 个人访问令牌：在正常模式下临时覆盖自动从 k8s Secret 解析出的生产令牌 (已废弃用法)；在 --no-k8s 模式下为必填项，直接作为生产令牌使用")
+	forkCmd.Flags().BoolVar(&noK8s, "no-k8s", false, "跳过 Kubernetes 依赖：不获取 kube config，不检查 source-group/target-group 对应命名空间是否存在，不通过 Secret 解析令牌；改为要求通过 --dev-token/--prod-token 直接提供令牌，且 --use-admin-token 必须为 'never' (管理员令牌同样依赖 k8s Secret)，用于集群外环境")
+	forkCmd.Flags().StringVar(&sourceBaseURLFlag, "source-base-url", "", "源项目所在 GitLab 实例的基础 URL，省略时回退到 --base-url；与 --target-base-url 不同时启用跨实例派生 (创建空项目 + 镜像克隆/推送)")
+	forkCmd.Flags().StringVar(&targetBaseURLFlag, "target-base-url", "", "目标组所在 GitLab 实例的基础 URL，省略时回退到 --base-url；与 --source-base-url 不同时启用跨实例派生 (创建空项目 + 镜像克隆/推送)")
+	forkCmd.Flags().BoolVar(&provisionCIVariables, "provision-ci-variables", false, "派生成功后在新项目上创建 PROMOTION_SOURCE_PROJECT/PROMOTION_SOURCE_SHA/PROMOTION_TAG 项目级 CI/CD 变量，记录本次派生的来源，供新项目自身流水线引用溯源信息")
+	forkCmd.Flags().StringVar(&promotedTag, "promoted-tag", "", "本次派生要记录的推广标签/版本号，写入 --provision-ci-variables 创建的 PROMOTION_TAG 变量")
+	forkCmd.Flags().BoolVar(&ensureTargetGroup, "ensure-target-group", false, "目标子组 (如 '<ns>/amlmodels') 不存在时自动创建，避免因子组尚未预先创建而派生失败")
+	forkCmd.Flags().StringVar(&ensureTargetGroupVisibility, "ensure-target-group-visibility", "private", "与 --ensure-target-group 搭配使用，自动创建目标子组时使用的可见性：'private'、'internal' 或 'public'")
+	forkCmd.Flags().StringVar(&ensureTargetGroupDescription, "ensure-target-group-description", "", "与 --ensure-target-group 搭配使用，自动创建目标子组时使用的描述")
+	forkCmd.Flags().StringVar(&settingsProfilePath, "settings-profile", "", "选择性设置复制策略文件 (YAML)，描述从源项目复制哪些类别的设置 (variables/protections/webhooks/members/badges/metadata) 到新派生项目；除本地文件路径外还支持 '-' (标准输入)、'http(s)://' URL、'configmap://<namespace>/<name>/<key>'")
+	forkCmd.Flags().BoolVar(&copyCIVariables, "copy-ci-variables", false, "派生成功后将源项目的 CI/CD 变量复制到新项目 (ForkProject API 本身不会带过去)，已存在同名变量时跳过而不覆盖")
+	forkCmd.Flags().BoolVar(&copyCIVariablesSkipProtected, "copy-ci-variables-skip-protected", false, "与 --copy-ci-variables 搭配使用，跳过受保护变量 (通常只应在其原本被保护的分支/环境上使用)")
+	forkCmd.Flags().BoolVar(&copyCIVariablesSkipMasked, "copy-ci-variables-skip-masked", false, "与 --copy-ci-variables 搭配使用，跳过 masked 变量 (取值往往就是密钥本身)")
+	forkCmd.Flags().BoolVar(&copyProtections, "copy-protections", false, "派生成功后将源项目的受保护分支与受保护标签规则复制到新项目 (ForkProject API 本身不会带过去)")
+	forkCmd.Flags().BoolVar(&copyWebhooks, "copy-webhooks", false, "派生成功后将源项目的 webhooks 复制到新项目 (ForkProject API 本身不会带过去)")
+	forkCmd.Flags().StringVar(&webhookSecretsFile, "webhook-secrets-file", "", "与 --copy-webhooks 搭配使用，按 {url: token} 映射源项目 webhook 密钥的 YAML 文件，用于补全 API 无法读回的 token (省略时复制出的 webhook 没有 token)。除本地文件路径外还支持 '-' (标准输入)、'http(s)://' URL、'configmap://<namespace>/<name>/<key>'")
+	forkCmd.Flags().BoolVar(&copyDeployKeys, "copy-deploy-keys", false, "派生成功后将源项目的部署密钥复制到新项目 (ForkProject API 本身不会带过去)")
+	forkCmd.Flags().BoolVar(&copyMembers, "copy-members", false, "派生成功后将源项目的直接成员与共享群组复制到新项目 (ForkProject API 本身不会带过去)")
+	forkCmd.Flags().StringVar(&copyMembersMinAccessLevel, "min-access-level", "", "与 --copy-members 搭配使用，只复制访问级别不低于该角色的成员 (guest/reporter/developer/maintainer/owner)，省略时复制全部成员")
+
+	for _, name := range []string{"exclude-subgroup", "exclude-project", "include-archived", "topic", "on-name-conflict", "setup-environments", "protect-production-environment", "idempotency-key", "target-name", "name-template", "name-pattern", "dry-run", "wait", "wait-timeout", "wait-poll-interval", "use-admin-token", "source-project-id", "manifest", "manifest-concurrency", "no-k8s", "ensure-target-group", "ensure-target-group-visibility", "ensure-target-group-description", "settings-profile", "if-exists", "copy-ci-variables", "copy-ci-variables-skip-protected", "copy-ci-variables-skip-masked", "copy-protections", "copy-webhooks", "webhook-secrets-file", "copy-deploy-keys", "copy-members", "min-access-level", "target-project-name", "target-path", "break-fork-relationship"} {
+		categorizeFlag(forkCmd, name, "behavior")
+	}
+	for _, name := range []string{"provision-badges", "dashboard-base-url", "topics", "target-visibility", "description", "description-template", "promoted-by", "ticket", "provision-ci-variables", "promoted-tag"} {
+		categorizeFlag(forkCmd, name, "output")
+	}
+	for _, name := range []string{"dev-token", "prod-token", "source-base-url", "target-base-url"} {
+		categorizeFlag(forkCmd, name, "auth")
+	}
+
+	// 注：source-group/source-project/target-group 在 --manifest 批量模式下并非必填 (改由 manifest
+	// 文件逐条目提供)，因此不通过 MarkFlagRequired 校验，而是在 Run 中按是否启用 --manifest 分别校验。
 }
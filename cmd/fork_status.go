@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
+	"github.com/spf13/cobra"
+)
+
+// 定义 fork-status 命令的参数变量
+var (
+	forkStatusGroup     string
+	forkStatusProject   string
+	forkStatusFormat    string
+	forkStatusThreshold time.Duration
+)
+
+// forkStatusReport 描述单个项目的派生健康状态，与 audit-divergence 的 divergenceReport
+// 共享同样的落后判定逻辑 (checkForkDivergence)，但只针对单个项目、用于人工快速自查而非批量告警
+type forkStatusReport struct {
+	Project                 string  `json:"project"`
+	ProjectID               int     `json:"project_id"`
+	ImportStatus            string  `json:"import_status,omitempty"`
+	ImportError             string  `json:"import_error,omitempty"`
+	IsFork                  bool    `json:"is_fork"`
+	Upstream                string  `json:"upstream,omitempty"`
+	UpstreamID              int     `json:"upstream_id,omitempty"`
+	DefaultBranchBehind     bool    `json:"default_branch_behind,omitempty"`
+	DefaultBranchLagSeconds float64 `json:"default_branch_lag_seconds,omitempty"`
+	LatestTagBehind         bool    `json:"latest_tag_behind,omitempty"`
+	LatestTagLagSeconds     float64 `json:"latest_tag_lag_seconds,omitempty"`
+}
+
+// forkStatusCmd 定义了 'fork-status' 子命令：给定单个目标项目，报告它是否为 fork、
+// 上游是谁、导入状态如何、以及默认分支/标签相对上游落后多少，作为一次推广后的快速健康检查，
+// 无需像 audit-divergence 那样遍历整个组。
+var forkStatusCmd = &cobra.Command{
+	Use:   "fork-status",
+	Short: "查看单个项目的派生状态、上游信息与落后情况",
+	Long: `给定 --group/--project，报告该项目是否为 fork、其上游项目是什么、导入状态 (import_status)，
+以及 (若为 fork) 默认分支/最新标签相对上游落后多少，作为一次推广后的快速健康检查。
+与 'audit-divergence' 共享同样的落后判定逻辑，但只针对单个项目而非遍历整个组。`,
+	Example: `  gitlab-fork-cli fork-status --group fy-prod --project iris
+  gitlab-fork-cli fork-status --group fy-prod --project iris --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if forkStatusGroup == "" || forkStatusProject == "" {
+			logFatal("❌ 错误: 必须提供 --group 与 --project 参数。")
+		}
+		if forkStatusFormat != "human" && forkStatusFormat != "json" {
+			logFatalf("❌ 错误: --format 只能是 'human' 或 'json'，收到: %s\n", forkStatusFormat)
+		}
+
+		ctx := cmd.Context()
+		kubeRestConfig, err := k8sutil.GetKubeConfig()
+		if err != nil {
+			logFatalf("❌ 无法获取 Kubernetes 配置: %v\n", err)
+		}
+		token, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, forkStatusGroup, GitlabSecretName, GitlabTokenKey)
+		if err != nil {
+			logFatalf("❌ 无法获取组 '%s' 的令牌: %v\n", forkStatusGroup, err)
+		}
+		client, err := newGitLabClient(token, baseURL, insecureSkip, caCertFile)
+		if err != nil {
+			logFatalf("❌ %v\n", err)
+		}
+
+		groupPath := getModelGroupByNs(forkStatusGroup)
+		projectID, err := findProjectInGroup(client, groupPath, forkStatusProject, groupEnumFilter{includeArchived: true})
+		if err != nil {
+			logFatalf("❌ 在组 '%s' 中未找到项目 '%s'。错误: %v\n", groupPath, forkStatusProject, err)
+		}
+
+		project, _, err := client.Projects.GetProject(projectID, nil)
+		if err != nil {
+			logFatalf("❌ 获取项目 (ID: %d) 详情失败: %v\n", projectID, err)
+		}
+
+		report := &forkStatusReport{
+			Project:      project.PathWithNamespace,
+			ProjectID:    project.ID,
+			ImportStatus: project.ImportStatus,
+			ImportError:  project.ImportError,
+			IsFork:       project.ForkedFromProject != nil,
+		}
+
+		if project.ForkedFromProject != nil {
+			divergence, err := checkForkDivergence(client, project, forkStatusThreshold)
+			if err != nil {
+				logFatalf("❌ 检查项目 '%s' 相对上游的落后情况失败: %v\n", project.PathWithNamespace, err)
+			}
+			report.Upstream = divergence.Upstream
+			report.UpstreamID = divergence.UpstreamID
+			report.DefaultBranchBehind = divergence.DefaultBranchBehind
+			report.DefaultBranchLagSeconds = divergence.DefaultBranchLagSeconds
+			report.LatestTagBehind = divergence.LatestTagBehind
+			report.LatestTagLagSeconds = divergence.LatestTagLagSeconds
+		}
+
+		if forkStatusFormat == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				logFatalf("❌ 序列化派生状态失败: %v\n", err)
+			}
+			return
+		}
+
+		fmt.Printf("项目: %s (ID: %d)\n", report.Project, report.ProjectID)
+		if report.ImportStatus != "" {
+			fmt.Printf("导入状态: %s\n", report.ImportStatus)
+		}
+		if report.ImportError != "" {
+			fmt.Printf("导入错误: %s\n", report.ImportError)
+		}
+		if !report.IsFork {
+			fmt.Println("是否为 fork: 否 (无派生关系，或已通过 --break-fork-relationship 解除)")
+			return
+		}
+		fmt.Printf("是否为 fork: 是，上游: %s (ID: %d)\n", report.Upstream, report.UpstreamID)
+		fmt.Printf("默认分支落后上游: %v (%.0f 秒)\n", report.DefaultBranchBehind, report.DefaultBranchLagSeconds)
+		if report.LatestTagLagSeconds != 0 || report.LatestTagBehind {
+			fmt.Printf("最新标签落后上游: %v (%.0f 秒)\n", report.LatestTagBehind, report.LatestTagLagSeconds)
+		} else {
+			fmt.Println("最新标签落后上游: 否 (上游无标签，或标签时间一致)")
+		}
+	},
+}
+
+func init() {
+	forkStatusCmd.Flags().StringVarP(&forkStatusGroup, "group", "g", "", "目标项目所在的 NS 名称 (必填)")
+	forkStatusCmd.Flags().StringVar(&forkStatusProject, "project", "", "目标项目名称 (必填)")
+	forkStatusCmd.Flags().StringVar(&forkStatusFormat, "format", "human", "输出格式: human 或 json")
+	forkStatusCmd.Flags().DurationVar(&forkStatusThreshold, "threshold", 72*time.Hour, "落后超过该时长的默认分支/标签会被标记为落后 (仅影响 default_branch_behind/latest_tag_behind 字段，lag_seconds 始终如实报告)")
+
+	categorizeFlag(forkStatusCmd, "format", "output")
+	categorizeFlag(forkStatusCmd, "threshold", "behavior")
+
+	forkStatusCmd.MarkFlagRequired("group")
+	forkStatusCmd.MarkFlagRequired("project")
+
+	rootCmd.AddCommand(forkStatusCmd)
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg"
+	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
+	"github.com/spf13/cobra"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// 定义 housekeep 命令的参数变量
+var (
+	housekeepGroup  string
+	housekeepKeep   int
+	housekeepDelete bool
+)
+
+// abSuffixPattern 匹配 --on-name-conflict=suffix 派生出的并行 A/B 版本命名 (base-2, base-3, ...)
+var abSuffixPattern = regexp.MustCompile(`^(.*)-\d+$`)
+
+// projectBaseName 返回项目的基础名称：去掉 A/B 派生追加的 "-N" 后缀 (若存在)
+func projectBaseName(name string) string {
+	if m := abSuffixPattern.FindStringSubmatch(name); m != nil {
+		return m[1]
+	}
+	return name
+}
+
+// housekeepCmd 定义了 'housekeep' 子命令
+var housekeepCmd = &cobra.Command{
+	Use:   "housekeep",
+	Short: "按 keep-latest 策略清理组内某个模型下过多的并行派生",
+	Long: `此命令按 --on-name-conflict=suffix 产生的命名约定 (base, base-2, base-3, ...)
+将同一目标组下的项目按基础名称分组，每组仅保留最近活跃的 N 个，其余的默认归档 (或使用 --delete 直接删除)。
+适合作为定期计划任务运行，清理批量 A/B 派生遗留下的旧版本。`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if housekeepGroup == "" {
+			logFatal("❌ 错误: 必须提供 --group 参数。")
+		}
+		if housekeepKeep < 1 {
+			logFatal("❌ 错误: --keep 必须 >= 1。")
+		}
+
+		warnings := pkg.NewWarningCollector()
+		warnings.SetStrict(strictMode)
+		warnings.SetCorrelationID(correlationID)
+		defer warnings.PrintSummary()
+		if insecureSkip {
+			if err := warnings.Add("insecure-tls", "已通过 --insecure 关闭 TLS 证书校验"); err != nil {
+				logFatalf("❌ %v", err)
+			}
+		}
+
+		ctx := cmd.Context()
+		kubeRestConfig, err := k8sutil.GetKubeConfig()
+		if err != nil {
+			logFatalf("❌ 无法获取 Kubernetes 配置。错误: %v\n", err)
+		}
+		token, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, housekeepGroup, GitlabSecretName, GitlabTokenKey)
+		if err != nil {
+			logFatalf("❌ 无法获取组 '%s' 的令牌。错误: %v\n", housekeepGroup, err)
+		}
+		git, err := newGitLabClient(token, baseURL, insecureSkip, caCertFile)
+		if err != nil {
+			logFatalf("❌ 创建 GitLab 客户端失败: %v\n", err)
+		}
+
+		groupPath := getModelGroupByNs(housekeepGroup)
+		log.Printf("ℹ️ 正在枚举组 '%s' 下的项目...\n", groupPath)
+
+		byBaseName := map[string][]*gitlab.Project{}
+		listOptions := &gitlab.ListGroupProjectsOptions{}
+		listOptions.PerPage = 100
+		listOptions.IncludeSubGroups = gitlab.Ptr(true)
+		for {
+			projects, resp, err := git.Groups.ListGroupProjects(groupPath, listOptions)
+			if err != nil {
+				logFatalf("❌ 列出组 '%s' 的项目失败: %v\n", groupPath, err)
+			}
+			for _, p := range projects {
+				base := projectBaseName(p.Name)
+				byBaseName[base] = append(byBaseName[base], p)
+			}
+			if resp.NextPage == 0 {
+				break
+			}
+			listOptions.Page = resp.NextPage
+		}
+
+		archived, deleted := 0, 0
+		for base, projects := range byBaseName {
+			if len(projects) <= housekeepKeep {
+				continue
+			}
+			sort.Slice(projects, func(i, j int) bool {
+				ti, tj := projects[i].LastActivityAt, projects[j].LastActivityAt
+				if ti == nil || tj == nil {
+					return false
+				}
+				return ti.After(*tj)
+			})
+
+			stale := projects[housekeepKeep:]
+			log.Printf("ℹ️ 模型 '%s' 共有 %d 个并行版本，超出 --keep=%d，将处理其中 %d 个旧版本。\n",
+				base, len(projects), housekeepKeep, len(stale))
+
+			for _, p := range stale {
+				if housekeepDelete {
+					if readOnlyGuard(fmt.Sprintf("删除项目 '%s'", p.PathWithNamespace)) {
+						continue
+					}
+					if _, err := git.Projects.DeleteProject(p.ID, nil); err != nil {
+						if warnErr := warnings.Add("housekeep-delete-failed", "删除项目 '%s' 失败: %v", p.PathWithNamespace, err); warnErr != nil {
+							logFatalf("❌ %v", warnErr)
+						}
+						log.Printf("⚠️ 删除项目 '%s' 失败: %v\n", p.PathWithNamespace, err)
+						continue
+					}
+					log.Printf("🗑️  已删除项目 '%s'。\n", p.PathWithNamespace)
+					deleted++
+				} else {
+					if readOnlyGuard(fmt.Sprintf("归档项目 '%s'", p.PathWithNamespace)) {
+						continue
+					}
+					if _, _, err := git.Projects.ArchiveProject(p.ID); err != nil {
+						if warnErr := warnings.Add("housekeep-archive-failed", "归档项目 '%s' 失败: %v", p.PathWithNamespace, err); warnErr != nil {
+							logFatalf("❌ %v", warnErr)
+						}
+						log.Printf("⚠️ 归档项目 '%s' 失败: %v\n", p.PathWithNamespace, err)
+						continue
+					}
+					log.Printf("📦 已归档项目 '%s'。\n", p.PathWithNamespace)
+					archived++
+				}
+			}
+		}
+
+		log.Printf("🎉 housekeep 完成，归档 %d 个，删除 %d 个。\n", archived, deleted)
+	},
+}
+
+func init() {
+	housekeepCmd.Flags().StringVar(&housekeepGroup, "group", "", "要清理的 NS 名称 (必填)")
+	housekeepCmd.Flags().IntVar(&housekeepKeep, "keep", 3, "每个模型保留的最近活跃版本数量")
+	housekeepCmd.Flags().BoolVar(&housekeepDelete, "delete", false, "直接删除旧版本而不是归档 (⚠️ 不可逆，默认仅归档)")
+
+	housekeepCmd.MarkFlagRequired("group")
+
+	rootCmd.AddCommand(housekeepCmd)
+}
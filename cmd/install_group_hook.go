@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
+	"github.com/spf13/cobra"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// installGroupHookSecretName/installGroupHookSecretKey 是本命令为组级 webhook 生成的
+// 签名密钥所使用的固定 Secret 名/key，与 GitlabSecretName/GitlabTokenKey (存放 GitLab 令牌)
+// 是不同用途的 Secret，因此单独命名，避免混淆或互相覆盖。
+const (
+	installGroupHookSecretName = "gitlab-webhook-secret"
+	installGroupHookSecretKey  = "GROUP_WEBHOOK_SECRET_TOKEN"
+)
+
+// 定义 install-group-hook 命令的参数变量
+var (
+	installGroupHookGroup            string
+	installGroupHookURL              string
+	installGroupHookSkipSSLVerify    bool
+	installGroupHookDisableTagEvents bool
+)
+
+// generateWebhookSecret 生成一个随机的 32 字节 (64 位十六进制字符) webhook 签名密钥
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成随机密钥失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// installGroupHookCmd 定义了 'install-group-hook' 子命令：在指定组上安装/更新一个组级 webhook，
+// 默认只订阅 tag push 事件，指向本工具 'listen' 模式 (或任意其他事件驱动推广触发器) 的入口 URL，
+// 并将签名密钥保存到该组所在命名空间的 k8s Secret 中，完成事件驱动推广闭环的"安装"这一步。
+var installGroupHookCmd = &cobra.Command{
+	Use:   "install-group-hook",
+	Short: "在指定组上安装/更新一个组级 webhook (默认订阅 tag push 事件)",
+	Long: `此命令在 --group 对应的 GitLab 组上安装一个组级 webhook，默认只订阅 tag push 事件，
+指向 --url 指定的事件监听端点 (如本工具的 'listen' 模式，或任意其他事件驱动推广触发器)。
+签名密钥随机生成并保存到该组所在命名空间的 k8s Secret ('gitlab-webhook-secret' 的
+'GROUP_WEBHOOK_SECRET_TOKEN' key) 中，供监听端点校验请求来源；若该 Secret 已存在有效密钥则复用，
+不会生成新密钥使已安装的 webhook 签名失效。若组上已存在指向相同 URL 的 webhook 则更新而不是重复创建。`,
+	Example: `  gitlab-fork-cli install-group-hook --group fy-dev --url https://promotion-listener.example.com/hooks/gitlab
+  gitlab-fork-cli install-group-hook --group fy-dev --url https://promotion-listener.example.com/hooks/gitlab --skip-ssl-verification`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if installGroupHookGroup == "" || installGroupHookURL == "" {
+			logFatal("❌ 错误: 必须提供 --group 与 --url 参数。")
+		}
+
+		ctx := cmd.Context()
+		kubeRestConfig, err := k8sutil.GetKubeConfig()
+		if err != nil {
+			logFatalf("❌ 无法获取 Kubernetes 配置: %v\n", err)
+		}
+		token, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, installGroupHookGroup, GitlabSecretName, GitlabTokenKey)
+		if err != nil {
+			logFatalf("❌ 无法获取组 '%s' 的令牌: %v\n", installGroupHookGroup, err)
+		}
+		client, err := newGitLabClient(token, baseURL, insecureSkip, caCertFile)
+		if err != nil {
+			logFatalf("❌ %v\n", err)
+		}
+
+		secretToken, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, installGroupHookGroup, installGroupHookSecretName, installGroupHookSecretKey)
+		if err != nil || secretToken == "" {
+			if readOnlyGuard(fmt.Sprintf("在命名空间 '%s' 中写入 webhook 签名密钥 Secret '%s'", installGroupHookGroup, installGroupHookSecretName)) {
+				return
+			}
+			secretToken, err = generateWebhookSecret()
+			if err != nil {
+				logFatalf("❌ %v\n", err)
+			}
+			if _, err := k8sutil.EnsureSecret(ctx, kubeRestConfig, installGroupHookGroup, installGroupHookSecretName, installGroupHookSecretKey, secretToken); err != nil {
+				logFatalf("❌ 保存 webhook 签名密钥失败: %v\n", err)
+			}
+		} else {
+			log.Printf("ℹ️ 复用命名空间 '%s' 中已存在的 webhook 签名密钥。\n", installGroupHookGroup)
+		}
+
+		if readOnlyGuard(fmt.Sprintf("在组 '%s' 上安装/更新指向 '%s' 的 webhook", getModelGroupByNs(installGroupHookGroup), installGroupHookURL)) {
+			return
+		}
+
+		groupPath := getModelGroupByNs(installGroupHookGroup)
+		hooks, _, err := client.Groups.ListGroupHooks(groupPath, nil)
+		if err != nil {
+			logFatalf("❌ 列出组 '%s' 的现有 webhook 失败: %v\n", groupPath, err)
+		}
+
+		tagPushEvents := !installGroupHookDisableTagEvents
+		enableSSLVerification := !installGroupHookSkipSSLVerify
+
+		var existing *gitlab.GroupHook
+		for _, h := range hooks {
+			if h.URL == installGroupHookURL {
+				existing = h
+				break
+			}
+		}
+
+		if existing != nil {
+			_, _, err := client.Groups.EditGroupHook(groupPath, existing.ID, &gitlab.EditGroupHookOptions{
+				URL:                   gitlab.Ptr(installGroupHookURL),
+				TagPushEvents:         gitlab.Ptr(tagPushEvents),
+				EnableSSLVerification: gitlab.Ptr(enableSSLVerification),
+				Token:                 gitlab.Ptr(secretToken),
+			})
+			if err != nil {
+				logFatalf("❌ 更新组 '%s' 上的 webhook (ID: %d) 失败: %v\n", groupPath, existing.ID, err)
+			}
+			log.Printf("🎉 已更新组 '%s' 上指向 '%s' 的 webhook (ID: %d)。\n", groupPath, installGroupHookURL, existing.ID)
+			return
+		}
+
+		created, _, err := client.Groups.AddGroupHook(groupPath, &gitlab.AddGroupHookOptions{
+			URL:                   gitlab.Ptr(installGroupHookURL),
+			TagPushEvents:         gitlab.Ptr(tagPushEvents),
+			EnableSSLVerification: gitlab.Ptr(enableSSLVerification),
+			Token:                 gitlab.Ptr(secretToken),
+		})
+		if err != nil {
+			logFatalf("❌ 在组 '%s' 上安装 webhook 失败: %v\n", groupPath, err)
+		}
+		log.Printf("🎉 已在组 '%s' 上安装指向 '%s' 的 webhook (ID: %d)。\n", groupPath, installGroupHookURL, created.ID)
+	},
+}
+
+func init() {
+	installGroupHookCmd.Flags().StringVarP(&installGroupHookGroup, "group", "g", "", "待安装 webhook 的 NS 名称 (必填)")
+	installGroupHookCmd.Flags().StringVar(&installGroupHookURL, "url", "", "webhook 指向的事件监听端点 URL (必填)")
+	installGroupHookCmd.Flags().BoolVar(&installGroupHookSkipSSLVerify, "skip-ssl-verification", false, "安装的 webhook 不校验监听端点的 TLS 证书 (仅用于自签名证书的内部监听端点)")
+	installGroupHookCmd.Flags().BoolVar(&installGroupHookDisableTagEvents, "disable-tag-events", false, "不订阅 tag push 事件 (默认订阅，用于触发按标签推广的事件驱动流水线)")
+
+	categorizeFlag(installGroupHookCmd, "skip-ssl-verification", "behavior")
+	categorizeFlag(installGroupHookCmd, "disable-tag-events", "behavior")
+
+	installGroupHookCmd.MarkFlagRequired("group")
+	installGroupHookCmd.MarkFlagRequired("url")
+
+	rootCmd.AddCommand(installGroupHookCmd)
+}
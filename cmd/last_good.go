@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"log"
+	"time"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg"
+	"github.com/spf13/cobra"
+)
+
+// 定义 last-good 命令的参数变量
+var (
+	lastGoodGroup   string // 目标项目所在的 NS 名称
+	lastGoodProject string // 目标项目名称
+)
+
+// lastGoodCmd 定义了 'last-good' 子命令，查询由 backport 记录的、某个目标项目最后一次
+// 成功回流的标签，事故响应人员据此可以立即知道应该回滚到哪个标签，而不必翻查回流历史。
+var lastGoodCmd = &cobra.Command{
+	Use:   "last-good",
+	Short: "查询目标项目最后一次成功回流的已知良好标签",
+	Long: `此命令读取本地晋级状态文件 (~/.gitlab-fork-cli/promotions.json)，该文件由每次
+成功的 'backport' 自动更新，记录每个 (--group, --project) 目标项目最后一次成功回流的
+标签、原因与时间。指定 --group 和 --project 时查询该目标项目；均省略时列出全部记录。
+
+事故响应时可先运行本命令确认最后已知良好的标签，再决定是否需要回滚。`,
+	Example: `  gitlab-fork-cli last-good --group fy-dev --project iris
+  gitlab-fork-cli last-good`,
+	Run: func(cmd *cobra.Command, args []string) {
+		statePath, err := pkg.DefaultPromotionStorePath()
+		if err != nil {
+			logFatalf("❌ 无法确定晋级状态文件路径: %v\n", err)
+		}
+		store := pkg.NewPromotionStore(statePath)
+
+		if lastGoodGroup == "" && lastGoodProject == "" {
+			records, err := store.All()
+			if err != nil {
+				logFatalf("❌ %v\n", err)
+			}
+			if len(records) == 0 {
+				log.Println("ℹ️ 目前还没有任何记录，尚未执行过成功的 backport。")
+				return
+			}
+			log.Printf("📋 共 %d 个目标项目有已知良好标签记录:\n", len(records))
+			for i, r := range records {
+				log.Printf("  %d. %s/%s: 标签 '%s' (%s，原因: %s)\n",
+					i+1, r.Group, r.Project, r.Tag, r.PromotedAt.Local().Format(time.RFC3339), r.Reason)
+			}
+			return
+		}
+
+		if lastGoodGroup == "" || lastGoodProject == "" {
+			logFatal("❌ 错误: --group 和 --project 必须同时提供，或都不提供以列出全部记录。")
+		}
+
+		record, ok, err := store.LastGood(lastGoodGroup, lastGoodProject)
+		if err != nil {
+			logFatalf("❌ %v\n", err)
+		}
+		if !ok {
+			logFatalf("❌ 目标项目 '%s/%s' 没有已知良好标签记录，可能从未通过 backport 成功回流过。\n", lastGoodGroup, lastGoodProject)
+		}
+
+		log.Printf("✅ '%s/%s' 最后已知良好标签: '%s'\n", record.Group, record.Project, record.Tag)
+		log.Printf("ℹ️ 回流时间: %s\n", record.PromotedAt.Local().Format(time.RFC3339))
+		log.Printf("ℹ️ 回流原因: %s\n", record.Reason)
+		log.Printf("💡 如需回滚，可重新将标签 '%s' backport 到受影响的环境。\n", record.Tag)
+	},
+}
+
+func init() {
+	lastGoodCmd.Flags().StringVar(&lastGoodGroup, "group", "", "目标项目所在的 NS 名称；与 --project 一起省略时列出全部记录")
+	lastGoodCmd.Flags().StringVar(&lastGoodProject, "project", "", "目标项目名称；与 --group 一起省略时列出全部记录")
+
+	rootCmd.AddCommand(lastGoodCmd)
+}
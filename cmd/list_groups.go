@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
+	"github.com/spf13/cobra"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"gopkg.in/yaml.v3"
+)
+
+// 定义 list-groups 命令的参数变量
+var (
+	listGroupsSearch         string // 按名称/路径模糊搜索
+	listGroupsTopLevelOnly   bool   // 只列出顶层组，不含子组
+	listGroupsMinAccessLevel string // 只列出令牌至少拥有该访问级别的组
+	listGroupsOutput         string // 输出格式：text/json/yaml
+)
+
+// listGroupsCmd 定义了 'list-groups' 子命令，用于在派生前发现令牌可见的目标组 (及其子组)，
+// 与 list-projects 按组列出项目互补：list-projects 需要先知道组名，本命令负责发现组名本身。
+var listGroupsCmd = &cobra.Command{
+	Use:   "list-groups",
+	Short: "列出令牌可见的 GitLab 组 (及子组)",
+	Long: `此命令列出管理员令牌可见的全部 GitLab 组，包括子组，
+用于在批量派生前先确认目标组是否存在，而不必登录 GitLab UI 逐个查找。
+可通过 --search 按名称/路径模糊筛选、--top-level-only 只保留顶层组、
+--min-access-level 只保留令牌至少拥有指定角色的组。`,
+	Example: `  gitlab-fork-cli list-groups
+  gitlab-fork-cli list-groups --search fy-prod
+  gitlab-fork-cli list-groups --top-level-only --output json
+  gitlab-fork-cli list-groups --min-access-level maintainer`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var minAccessLevel *gitlab.AccessLevelValue
+		if listGroupsMinAccessLevel != "" {
+			level, ok := groupAccessLevelByName[strings.ToLower(listGroupsMinAccessLevel)]
+			if !ok {
+				logFatalf("❌ 无效的 --min-access-level '%s'，可选值: guest, reporter, developer, maintainer, owner。\n", listGroupsMinAccessLevel)
+			}
+			minAccessLevel = gitlab.Ptr(level)
+		}
+
+		ctx := cmd.Context()
+		kubeRestConfig, err := k8sutil.GetKubeConfig()
+		if err != nil {
+			logFatalf("❌ 无法获取 Kubernetes 配置，无法获取 Secret。错误: %v\n", err)
+		}
+		token, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, "kubeflow", GitlabSecretName, GitlabTokenKey)
+		if err != nil {
+			logFatalf("❌ 无法获取管理员令牌。错误: %v\n", err)
+		}
+
+		log.Printf("ℹ️ 正在创建 GitLab 客户端 (%s)...\n", baseURL)
+		git, err := newGitLabClient(token, baseURL, insecureSkip, caCertFile)
+		if err != nil {
+			logFatalf("❌ %v", err)
+		}
+
+		listOptions := &gitlab.ListGroupsOptions{
+			AllAvailable: gitlab.Ptr(true),
+		}
+		listOptions.PerPage = 100
+		if listGroupsSearch != "" {
+			listOptions.Search = gitlab.Ptr(listGroupsSearch)
+		}
+		if listGroupsTopLevelOnly {
+			listOptions.TopLevelOnly = gitlab.Ptr(true)
+		}
+		if minAccessLevel != nil {
+			listOptions.MinAccessLevel = minAccessLevel
+		}
+
+		log.Println("🚀 正在获取令牌可见的组...")
+
+		var jsonEncoder *json.Encoder
+		var yamlEncoder *yaml.Encoder
+		switch strings.ToLower(listGroupsOutput) {
+		case "json":
+			fmt.Print("[")
+			jsonEncoder = json.NewEncoder(os.Stdout)
+		case "yaml":
+			yamlEncoder = yaml.NewEncoder(os.Stdout)
+			defer yamlEncoder.Close()
+		case "text", "":
+			// no-op，走下方默认的按行文本输出
+		default:
+			logFatalf("❌ 无效的 --output '%s'，可选值: text, json, yaml。\n", listGroupsOutput)
+		}
+
+		matched := 0
+		for {
+			groups, resp, err := git.Groups.ListGroups(listOptions)
+			if err != nil {
+				logFatalf("❌ 列出组失败: %v", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				logFatalf("❌ 列出组失败，HTTP 状态码: %d", resp.StatusCode)
+			}
+
+			for _, g := range groups {
+				matched++
+				switch {
+				case jsonEncoder != nil:
+					if matched > 1 {
+						fmt.Print(",")
+					}
+					if err := jsonEncoder.Encode(g); err != nil {
+						logFatalf("❌ 序列化组 '%s' 失败: %v", g.FullPath, err)
+					}
+				case yamlEncoder != nil:
+					if err := yamlEncoder.Encode(g); err != nil {
+						logFatalf("❌ 序列化组 '%s' 失败: %v", g.FullPath, err)
+					}
+				default:
+					log.Printf("  %d. %s (ID: %d, 路径: %s, 可见性: %s)\n",
+						matched, g.Name, g.ID, g.FullPath, g.Visibility)
+				}
+			}
+
+			if resp.NextPage == 0 {
+				break // 没有更多页了
+			}
+			listOptions.Page = resp.NextPage
+		}
+
+		if jsonEncoder != nil {
+			fmt.Println("]")
+		} else if matched == 0 {
+			log.Println("ℹ️ 没有找到任何匹配的组。")
+		}
+
+		log.Printf("✅ 操作完成，共匹配 %d 个组。\n", matched)
+	},
+}
+
+func init() {
+	listGroupsCmd.Flags().StringVar(&listGroupsSearch, "search", "", "按名称/路径模糊搜索组")
+	listGroupsCmd.Flags().BoolVar(&listGroupsTopLevelOnly, "top-level-only", false, "只列出顶层组，不包含子组")
+	listGroupsCmd.Flags().StringVar(&listGroupsMinAccessLevel, "min-access-level", "", "只列出令牌至少拥有该访问级别的组：guest, reporter, developer, maintainer, owner")
+	listGroupsCmd.Flags().StringVar(&listGroupsOutput, "output", "text", "输出格式：'text' (默认)、'json' 或 'yaml'")
+
+	for _, name := range []string{"search", "top-level-only", "min-access-level"} {
+		categorizeFlag(listGroupsCmd, name, "behavior")
+	}
+	categorizeFlag(listGroupsCmd, "output", "output")
+
+	rootCmd.AddCommand(listGroupsCmd)
+}
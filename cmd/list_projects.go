@@ -1,16 +1,18 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"github.com/fy1316/gitlab-fork-cli/pkg"
 	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
 	"go.uber.org/zap"
 	"log"
-	"net/http"
 	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"gopkg.in/yaml.v3"
 )
 
 // 定义 list-projects 命令的参数变量
@@ -18,6 +20,7 @@ var (
 	listGroup      string
 	listToken      string
 	listVisibility string
+	listOutput     string
 )
 
 // listProjectsCmd 定义了 'list-projects' 子命令
@@ -25,11 +28,11 @@ var listProjectsCmd = &cobra.Command{
 	Use:   "list-projects",
 	Short: "列出指定 GitLab 组下的所有项目",
 	Long: `此命令用于列出指定 GitLab 组下的所有项目。
-可以根据可见性 (public, private, internal) 进行筛选。
-
-例如:
-  gitlab-fork-cli list-projects --group my-dev --token <your_token>
-  gitlab-fork-cli list-projects --group my-prod --token <your_token> --visibility public`,
+可以根据可见性 (public, private, internal) 进行筛选。`,
+	Example: `  gitlab-fork-cli list-projects --group my-dev
+  gitlab-fork-cli list-projects --group my-prod --visibility public
+  gitlab-fork-cli list-projects --group my-prod --output json --include-archived
+  gitlab-fork-cli list-projects --group my-prod --output yaml`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// 检查必填参数
 		if listGroup == "" {
@@ -38,6 +41,16 @@ var listProjectsCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		warnings := pkg.NewWarningCollector()
+		warnings.SetStrict(strictMode)
+		warnings.SetCorrelationID(correlationID)
+		defer warnings.PrintSummary()
+		if insecureSkip {
+			if err := warnings.Add("insecure-tls", "已通过 --insecure 关闭 TLS 证书校验"); err != nil {
+				logFatalf("❌ %v", err)
+			}
+		}
+
 		// 验证 visibility 参数
 		if listVisibility != "" {
 			validVisibilities := map[string]struct{}{
@@ -46,43 +59,42 @@ var listProjectsCmd = &cobra.Command{
 				"internal": {},
 			}
 			if _, ok := validVisibilities[strings.ToLower(listVisibility)]; !ok {
-				log.Fatalf("❌ 错误: 无效的可见性参数 '%s'。有效值: public, private, internal。", listVisibility)
+				logFatalf("❌ 错误: 无效的可见性参数 '%s'。有效值: public, private, internal。", listVisibility)
 			}
 		}
+		ctx := cmd.Context()
 		kubeRestConfig, err := k8sutil.GetKubeConfig()
 		if err != nil {
-			log.Fatalf("❌ 无法获取 Kubernetes 配置，无法检查命名空间或获取 Secret。错误: %v\n", err)
+			logFatalf("❌ 无法获取 Kubernetes 配置，无法检查命名空间或获取 Secret。错误: %v\n", err)
 		}
-		token, err := k8sutil.GetSecretValue(kubeRestConfig, "kubeflow", GitlabSecretName, GitlabTokenKey)
+		token, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, "kubeflow", GitlabSecretName, GitlabTokenKey)
 		//token, err := getTokenFromSecret(listGroup, GitlabSecretName, GitlabTokenKey)
 		if err != nil {
-			log.Fatal("❌ 无法获取开发令牌。请确认输入的 group 对应的 Secret 存在且可访问。",
+			logFatal("❌ 无法获取开发令牌。请确认输入的 group 对应的 Secret 存在且可访问。",
 				zap.String("group", sourceGroup),
 				zap.Error(err))
 		}
+		if listToken != "" {
+			log.Println("⚠️ 检测到已废弃标志 --token，将覆盖自动获取的令牌。请尽快迁移到基于 k8s Secret 的令牌解析。")
+			token = listToken
+		}
 		// 1. 创建 GitLab 客户端
 
 		log.Printf("ℹ️ 正在创建 GitLab 客户端 (%s)...\n", baseURL)
-		git, err := newGitLabClient(token, baseURL, insecureSkip)
+		git, err := newGitLabClient(token, baseURL, insecureSkip, caCertFile)
 		if err != nil {
-			log.Fatalf("❌ %v", err)
+			logFatalf("❌ %v", err)
 		}
 
-		// 2. 设置项目列表选项
-		listOptions := &gitlab.ListGroupProjectsOptions{}
-		listOptions.PerPage = 100
-		listOptions.IncludeSubGroups = gitlab.Ptr(true)
-
-		// 根据可见性参数设置筛选条件
-		if listVisibility != "" {
-			switch strings.ToLower(listVisibility) {
-			case "public":
-				listOptions.Visibility = gitlab.Ptr(gitlab.PublicVisibility)
-			case "private":
-				listOptions.Visibility = gitlab.Ptr(gitlab.PrivateVisibility)
-			case "internal":
-				listOptions.Visibility = gitlab.Ptr(gitlab.InternalVisibility)
-			}
+		// 2. 根据可见性参数设置筛选条件
+		var visibility gitlab.VisibilityValue
+		switch strings.ToLower(listVisibility) {
+		case "public":
+			visibility = gitlab.PublicVisibility
+		case "private":
+			visibility = gitlab.PrivateVisibility
+		case "internal":
+			visibility = gitlab.InternalVisibility
 		}
 
 		log.Printf("🚀 正在获取组 '%s' 下的项目 (可见性: %s)...\n", listGroup, func() string {
@@ -92,55 +104,86 @@ var listProjectsCmd = &cobra.Command{
 			return listVisibility
 		}())
 
-		// 3. 循环遍历所有页，获取项目列表
-		allProjects := []*gitlab.Project{}
-		for {
-			projects, resp, err := git.Groups.ListGroupProjects(listGroup, listOptions)
-			if err != nil {
-				log.Fatalf("❌ 列出组 '%s' 的项目失败: %v", listGroup, err)
-			}
-			if resp.StatusCode != http.StatusOK {
-				log.Fatalf("❌ 列出组 '%s' 的项目失败，HTTP 状态码: %d", listGroup, resp.StatusCode)
-			}
-
-			allProjects = append(allProjects, projects...)
+		// 3. 逐页流式处理项目列表，不在内存中累积全量结果，避免大型实例下内存随组规模线性增长
+		filter := groupEnumFilter{excludeSubgroups: excludeSubgroupPatterns, excludeProjects: excludeProjectPatterns, includeArchived: includeArchived, topics: topicFilter}
+
+		var jsonEncoder *json.Encoder
+		var yamlEncoder *yaml.Encoder
+		switch strings.ToLower(listOutput) {
+		case "json":
+			fmt.Print("[")
+			jsonEncoder = json.NewEncoder(os.Stdout)
+		case "yaml":
+			yamlEncoder = yaml.NewEncoder(os.Stdout)
+			defer yamlEncoder.Close()
+		case "text", "":
+			// no-op，走下方默认的按行文本输出
+		default:
+			logFatalf("❌ 无效的 --output '%s'，可选值: text, json, yaml。\n", listOutput)
+		}
 
-			if resp.NextPage == 0 {
-				break // 没有更多页了
+		matched := 0
+		err = pkg.ListGroupProjects(ctx, pkg.ListGroupProjectsRequest{Client: git, GroupPath: listGroup, Visibility: visibility}, func(p *gitlab.Project) error {
+			if excluded, reason := filter.excludes(p); excluded {
+				log.Printf("⏭️  跳过项目 '%s': %s\n", p.NameWithNamespace, reason)
+				return nil
 			}
-			listOptions.Page = resp.NextPage
+			matched++
+			switch {
+			case jsonEncoder != nil:
+				if matched > 1 {
+					fmt.Print(",")
+				}
+				if err := jsonEncoder.Encode(p); err != nil {
+					return fmt.Errorf("序列化项目 '%s' 失败: %w", p.PathWithNamespace, err)
+				}
+			case yamlEncoder != nil:
+				if err := yamlEncoder.Encode(p); err != nil {
+					return fmt.Errorf("序列化项目 '%s' 失败: %w", p.PathWithNamespace, err)
+				}
+			default:
+				log.Printf("  %d. %s (ID: %d, 路径: %s, 可见性: %s)\n",
+					matched, p.NameWithNamespace, p.ID, p.PathWithNamespace, p.Visibility)
+			}
+			return nil
+		})
+		if err != nil {
+			logFatalf("❌ %v", err)
 		}
 
-		// 4. 打印项目信息
-		if len(allProjects) == 0 {
+		if jsonEncoder != nil {
+			fmt.Println("]")
+		} else if matched == 0 {
 			log.Printf("ℹ️ 组 '%s' (可见性: %s) 下没有找到任何项目。\n", listGroup, func() string {
 				if listVisibility == "" {
 					return "所有"
 				}
 				return listVisibility
 			}())
-		} else {
-			log.Printf("\n🎉 组 '%s' (可见性: %s) 下的项目列表 (%d 个):\n", listGroup, func() string {
-				if listVisibility == "" {
-					return "所有"
-				}
-				return listVisibility
-			}(), len(allProjects))
-			for i, p := range allProjects {
-				log.Printf("  %d. %s (ID: %d, 路径: %s, 可见性: %s)\n",
-					i+1, p.NameWithNamespace, p.ID, p.PathWithNamespace, p.Visibility)
-			}
 		}
 
-		log.Println("✅ 操作完成。")
+		log.Printf("✅ 操作完成，共匹配 %d 个项目。\n", matched)
 	},
 }
 
 func init() {
 	// 定义 list-projects 命令的本地标志
 	listProjectsCmd.Flags().StringVarP(&listGroup, "group", "g", "", "项目 NS 的名称")
-	//listProjectsCmd.Flags().StringVarP(&listToken, "token", "t", "", "用于访问 GitLab API 的个人访问令牌")
+	// 已废弃标志的兼容层：令牌现自动从 k8s Secret 解析，此处保留旧标志名并标记为 deprecated
+	listProjectsCmd.Flags().StringVar(&listToken, "token", "", "(已废弃) 用于访问 GitLab API 的个人访问令牌，现自动从 k8s Secret 解析")
+	listProjectsCmd.Flags().MarkDeprecated("token", "令牌现自动从 k8s Secret 解析，此标志仅用于临时覆盖")
+	categorizeFlag(listProjectsCmd, "token", "auth")
 	listProjectsCmd.Flags().StringVarP(&listVisibility, "visibility", "v", "", "可选: 按可见性筛选项目 (public, private, internal)")
+	listProjectsCmd.Flags().StringSliceVar(&excludeSubgroupPatterns, "exclude-subgroup", nil, "排除子组的 glob 模式，可重复指定，用于批量操作时跳过归档/沙箱子组")
+	listProjectsCmd.Flags().StringSliceVar(&excludeProjectPatterns, "exclude-project", nil, "排除项目的 glob 模式，可重复指定，用于批量操作时跳过特定项目")
+	listProjectsCmd.Flags().BoolVar(&includeArchived, "include-archived", false, "包含已归档的项目 (默认跳过归档项目)")
+	listProjectsCmd.Flags().StringSliceVar(&topicFilter, "topic", nil, "仅列出携带指定 topic 之一的项目，可重复指定或用逗号分隔 (如 model,llm)")
+	listProjectsCmd.Flags().StringVar(&listOutput, "output", "text", "输出格式：'text' (默认)、'json' 或 'yaml' (均为流式输出，内存占用不随组规模增长)")
+
+	for _, name := range []string{"visibility", "exclude-subgroup", "exclude-project", "include-archived", "topic"} {
+		categorizeFlag(listProjectsCmd, name, "behavior")
+	}
+	categorizeFlag(listProjectsCmd, "output", "output")
 
 	// 标记这些标志为必填
 	listProjectsCmd.MarkFlagRequired("group")
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
+	"github.com/spf13/cobra"
+)
+
+// 定义 listen 命令的参数变量
+var (
+	listenAddr        string // webhook 服务监听地址
+	listenGroup       string // 源项目所在的 NS，同时用于解析 install-group-hook 生成的 webhook 签名密钥
+	listenTargetGroup string // 收到匹配事件后派生到的目标 NS
+)
+
+// gitlabTagPushEvent 是 GitLab tag push / release webhook 事件体中，触发派生所需的最小字段子集，
+// 参见 https://docs.gitlab.com/ee/user/project/integrations/webhook_events.html
+type gitlabTagPushEvent struct {
+	ObjectKind string `json:"object_kind"`
+	Ref        string `json:"ref"`
+	Project    struct {
+		Name              string `json:"name"`
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+}
+
+// listenCmd 定义了 'listen' 子命令：接收 --group 对应组上由 'install-group-hook' 安装的 tag push /
+// release webhook，校验请求头 X-Gitlab-Token 与该组命名空间中签名密钥一致后，异步触发一次 'fork'，
+// 将事件所属项目派生到 --target-group。这是固定的单一源组到单一目标组映射；按规则匹配多个源/目标
+// 尚未实现 (见 docs/listen-mode-notes.md 中 mapping-rules-engine 相关请求的记录)，事件持久化/失败
+// 重放/死信队列同样尚未实现 (见同一文件)。
+var listenCmd = &cobra.Command{
+	Use:   "listen",
+	Short: "接收 GitLab tag push webhook 并自动触发派生",
+	Long: `此命令启动一个 HTTP 服务，接收 --group 对应组上由 'install-group-hook' 安装的 webhook 事件
+(POST /hooks/gitlab)。校验请求头 X-Gitlab-Token 与该组命名空间中 'gitlab-webhook-secret' Secret
+内保存的签名密钥一致后，对 tag_push / release 事件异步触发一次 'fork'，将事件所属项目派生到
+--target-group，从而取消发布流程中手动派生这一步。
+本命令是固定的单一源组到单一目标组映射，尚不支持按规则匹配多个源/目标；也不持久化收到的事件，
+进程重启会丢失尚在处理中的事件。`,
+	Example: `  gitlab-fork-cli listen --group my-dev --target-group my-prod --addr :8090`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if listenGroup == "" || listenTargetGroup == "" {
+			logFatal("❌ 错误: 必须提供 --group 和 --target-group 参数。")
+		}
+
+		ctx := cmd.Context()
+		kubeRestConfig, err := k8sutil.GetKubeConfig()
+		if err != nil {
+			logFatalf("❌ 无法获取 Kubernetes 配置: %v\n", err)
+		}
+		secretToken, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, listenGroup, installGroupHookSecretName, installGroupHookSecretKey)
+		if err != nil {
+			logFatalf("❌ 无法获取组 '%s' 的 webhook 签名密钥，请先运行 'install-group-hook': %v\n", listenGroup, err)
+		}
+
+		exePath, err := os.Executable()
+		if err != nil {
+			logFatalf("❌ 无法定位当前可执行文件用于触发派生: %v\n", err)
+		}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("ok"))
+		})
+		mux.HandleFunc("POST /hooks/gitlab", func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Gitlab-Token") != secretToken {
+				log.Println("❌ 拒绝了一个 X-Gitlab-Token 不匹配的 webhook 请求。")
+				http.Error(w, `{"error": "invalid token"}`, http.StatusUnauthorized)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, `{"error": "无法读取请求体"}`, http.StatusBadRequest)
+				return
+			}
+			var event gitlabTagPushEvent
+			if err := json.Unmarshal(body, &event); err != nil {
+				http.Error(w, `{"error": "请求体不是合法 JSON"}`, http.StatusBadRequest)
+				return
+			}
+			if event.ObjectKind != "tag_push" && event.ObjectKind != "release" {
+				log.Printf("ℹ️ 忽略事件类型 '%s' (只处理 tag_push/release)。\n", event.ObjectKind)
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+			if event.Project.Name == "" {
+				http.Error(w, `{"error": "事件缺少 project.name"}`, http.StatusBadRequest)
+				return
+			}
+
+			log.Printf("🚀 收到项目 '%s' (ref: %s) 的 %s 事件，正在异步触发派生到 '%s'...\n",
+				event.Project.PathWithNamespace, event.Ref, event.ObjectKind, listenTargetGroup)
+			sourceProject := event.Project.Name
+			go func() {
+				args := []string{"fork",
+					"--source-group", listenGroup,
+					"--source-project", sourceProject,
+					"--target-group", listenTargetGroup,
+				}
+				if _, err := runPrefixedSubcommand(exePath, args, fmt.Sprintf("webhook:%s", sourceProject)); err != nil {
+					log.Printf("❌ 由 webhook 事件触发的派生失败 (项目 '%s'): %v\n", sourceProject, err)
+				}
+			}()
+			w.WriteHeader(http.StatusAccepted)
+		})
+
+		log.Printf("ℹ️ 正在监听 %s 上的 GitLab webhook (源组: %s, 目标组: %s)...\n", listenAddr, listenGroup, listenTargetGroup)
+		if err := http.ListenAndServe(listenAddr, mux); err != nil {
+			logFatalf("❌ 服务启动失败: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	listenCmd.Flags().StringVar(&listenAddr, "addr", ":8090", "webhook 服务监听地址")
+	listenCmd.Flags().StringVar(&listenGroup, "group", "", "源项目所在的 NS 名称，同时用于解析 install-group-hook 生成的 webhook 签名密钥 (必填)")
+	listenCmd.Flags().StringVar(&listenTargetGroup, "target-group", "", "收到匹配事件后派生到的目标 NS 名称 (必填)")
+
+	categorizeFlag(listenCmd, "group", "behavior")
+	categorizeFlag(listenCmd, "target-group", "behavior")
+
+	listenCmd.MarkFlagRequired("group")
+	listenCmd.MarkFlagRequired("target-group")
+
+	rootCmd.AddCommand(listenCmd)
+}
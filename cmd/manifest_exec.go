@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bytes"
+	"log"
+	"os/exec"
+)
+
+// prefixedWriter 将写入的内容按行加上 [prefix] 前缀实时打印，同时原样缓存完整内容，
+// 用于批量 (--manifest) 模式下并发执行多个条目时区分各自的日志输出，
+// 缓存的完整内容供调用方在条目失败时二次展示 (如 --verbose 模式)。
+type prefixedWriter struct {
+	prefix string
+	buf    bytes.Buffer
+	line   bytes.Buffer
+}
+
+func (w *prefixedWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for _, b := range p {
+		if b == '\n' {
+			log.Printf("[%s] %s\n", w.prefix, w.line.String())
+			w.line.Reset()
+			continue
+		}
+		w.line.WriteByte(b)
+	}
+	return len(p), nil
+}
+
+// flush 打印尚未以换行符结尾的最后一部分内容 (若有)
+func (w *prefixedWriter) flush() {
+	if w.line.Len() > 0 {
+		log.Printf("[%s] %s\n", w.prefix, w.line.String())
+		w.line.Reset()
+	}
+}
+
+// runPrefixedSubcommand 执行 exePath 及 args，将其 stdout/stderr 合并后按行加前缀实时打印，
+// 返回完整的合并输出 (不含前缀) 与命令的退出错误，供批量模式的每个并发条目使用。
+func runPrefixedSubcommand(exePath string, args []string, prefix string) (string, error) {
+	c := exec.Command(exePath, args...)
+	pw := &prefixedWriter{prefix: prefix}
+	c.Stdout = pw
+	c.Stderr = pw
+	err := c.Run()
+	pw.flush()
+	return pw.buf.String(), err
+}
@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// 定义 mirror 命令的参数变量
+var (
+	mirrorFromRepoURL   string // 源 Git 仓库地址 (与 --from-project 二选一)
+	mirrorFromProject   string // 源项目路径 (与 --from-repo-url 二选一)
+	mirrorFromToken     string // 源仓库用于认证的个人访问令牌
+	mirrorToRepoURL     string // 目的 Git 仓库地址 (与 --to-project 二选一)
+	mirrorToProject     string // 目的项目路径 (与 --to-repo-url 二选一)
+	mirrorToToken       string // 目的仓库用于认证的个人访问令牌
+	mirrorOutputDir     string // 镜像克隆到的本地目录
+	mirrorForce         bool   // 强制推送，覆盖目标仓库上非快进的分支/标签
+	mirrorAtomic        bool   // 以原子方式推送所有分支与标签，任意一个被拒绝则整体回滚
+	mirrorPrune         bool   // 删除目标仓库上源仓库已不存在的分支/标签，实现完整的 --mirror 语义
+	mirrorYes           bool   // 与 --prune 搭配的显式确认，避免误删引用
+	mirrorFromTokenFile string // 从文件读取源仓库令牌
+	mirrorToTokenFile   string // 从文件读取目的仓库令牌
+	mirrorPromptToken   bool   // 交互式从终端读取令牌 (不回显)
+
+	mirrorManifestPath        string // 批量镜像模式：从 YAML 来源读取一组 {from, to} 条目
+	mirrorManifestConcurrency int    // 批量镜像模式的并发度
+)
+
+// mirrorManifestEntry 描述 --manifest 文件中的一条批量镜像条目，from/to 各自要求 *-repo-url 与
+// *-project 二选一，与 mirror 命令单次调用时的寻址约定一致。
+type mirrorManifestEntry struct {
+	FromRepoURL string `yaml:"from-repo-url"`
+	FromProject string `yaml:"from-project"`
+	ToRepoURL   string `yaml:"to-repo-url"`
+	ToProject   string `yaml:"to-project"`
+}
+
+// mirrorManifestResult 记录批量镜像中单个条目的执行结果
+type mirrorManifestResult struct {
+	Entry   mirrorManifestEntry
+	Success bool
+	Output  string
+	Err     error
+}
+
+// mirrorManifestEntryLabel 返回用于日志前缀/结果汇总的条目标识 (优先使用 project 路径，
+// 二者都缺省时退回 repo-url)
+func mirrorManifestEntryLabel(entry mirrorManifestEntry) string {
+	from := entry.FromProject
+	if from == "" {
+		from = entry.FromRepoURL
+	}
+	to := entry.ToProject
+	if to == "" {
+		to = entry.ToRepoURL
+	}
+	return fmt.Sprintf("%s -> %s", from, to)
+}
+
+// loadMirrorManifest 读取并解析 --manifest 指定的来源 (与 fork --manifest 共享 readSource 支持的
+// 来源类型：本地文件路径、"-"/标准输入、"http(s)://" URL、"configmap://<namespace>/<name>/<key>")
+func loadMirrorManifest(ctx context.Context, source string) ([]mirrorManifestEntry, error) {
+	data, err := readSource(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("读取 manifest '%s' 失败: %w", source, err)
+	}
+
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("解析 manifest '%s' 失败: %w", source, err)
+	}
+	if err := pkg.ValidateAgainstSchema("mirror-manifest", raw); err != nil {
+		return nil, fmt.Errorf("manifest '%s' 不符合 mirror-manifest schema: %w", source, err)
+	}
+
+	var entries []mirrorManifestEntry
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("解析 manifest '%s' 失败: %w", source, err)
+	}
+	return entries, nil
+}
+
+// passthroughMirrorFlags 收集本次调用中除批量模式自身与按条目指定的寻址标志外、被显式设置的
+// 其余 mirror 标志，原样透传给每个条目对应的子调用 (与 passthroughForkFlags 同一模式)。
+func passthroughMirrorFlags(cmd *cobra.Command) []string {
+	skip := map[string]bool{
+		"manifest": true, "manifest-concurrency": true,
+		"from-repo-url": true, "from-project": true, "to-repo-url": true, "to-project": true,
+	}
+	var out []string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if skip[f.Name] {
+			return
+		}
+		out = append(out, fmt.Sprintf("--%s=%s", f.Name, f.Value.String()))
+	})
+	return out
+}
+
+// runMirrorManifest 以 --manifest-concurrency 指定的并发度，将 manifest 中的每个条目作为独立的
+// 子进程 (重新调用当前可执行文件的 'mirror' 子命令) 执行，与 runForkManifest 同一模式：一个条目
+// 的失败不影响其余条目，子进程输出按条目加前缀实时打印，结束后打印逐条目的成功/失败汇总。
+func runMirrorManifest(cmd *cobra.Command, manifestPath string, concurrency int) {
+	entries, err := loadMirrorManifest(cmd.Context(), manifestPath)
+	if err != nil {
+		logFatalf("❌ %v\n", err)
+	}
+	if len(entries) == 0 {
+		logFatal("❌ manifest 文件中没有任何条目。")
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		logFatalf("❌ 无法定位当前可执行文件用于批量镜像子调用: %v\n", err)
+	}
+	passthrough := passthroughMirrorFlags(cmd)
+
+	log.Printf("🚀 正在从 manifest '%s' 批量镜像 %d 个条目 (并发度: %d)...\n", manifestPath, len(entries), concurrency)
+
+	results := make([]mirrorManifestResult, len(entries))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, entry := range entries {
+		if (entry.FromRepoURL == "" && entry.FromProject == "") || (entry.ToRepoURL == "" && entry.ToProject == "") {
+			results[i] = mirrorManifestResult{Entry: entry, Err: fmt.Errorf("条目缺少 from-repo-url/from-project 或 to-repo-url/to-project")}
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, entry mirrorManifestEntry) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			var args []string
+			args = append(args, "mirror")
+			if entry.FromRepoURL != "" {
+				args = append(args, "--from-repo-url", entry.FromRepoURL)
+			} else {
+				args = append(args, "--from-project", entry.FromProject)
+			}
+			if entry.ToRepoURL != "" {
+				args = append(args, "--to-repo-url", entry.ToRepoURL)
+			} else {
+				args = append(args, "--to-project", entry.ToProject)
+			}
+			args = append(args, passthrough...)
+
+			output, err := runPrefixedSubcommand(exePath, args, mirrorManifestEntryLabel(entry))
+			results[i] = mirrorManifestResult{Entry: entry, Success: err == nil, Output: output, Err: err}
+		}(i, entry)
+	}
+	wg.Wait()
+
+	succeeded, failed := 0, 0
+	log.Println("\n📦 批量镜像结果汇总:")
+	for _, r := range results {
+		label := mirrorManifestEntryLabel(r.Entry)
+		if r.Err == nil {
+			succeeded++
+			log.Printf("  ✅ %s\n", label)
+			continue
+		}
+		failed++
+		log.Printf("  ❌ %s: %v\n", label, r.Err)
+	}
+	log.Printf("\n🎉 批量镜像完成，共 %d 个条目，成功 %d 个，失败 %d 个。\n", len(entries), succeeded, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// mirrorCmd 定义了 'mirror' 子命令
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror",
+	Short: "对源仓库执行完整的 git --mirror 克隆并推送到目标仓库",
+	Long: `此命令对源仓库执行 bare mirror 克隆 (全部分支、全部标签)，随后将完整的引用集合
+推送到目标仓库，用于让长期存在的生产镜像追平源仓库，而不是像 'clone' 命令那样只处理单一引用。
+与 'sync-fork' 不同，本命令不依赖 GitLab 的 fork 关系，源/目标仓库通过 --from-*/--to-* 显式指定。`,
+	Example: `  gitlab-fork-cli mirror --from-repo-url https://gitlab.example.com/dev/app.git --to-repo-url https://gitlab.example.com/prod/app.git
+  gitlab-fork-cli mirror --from-project dev/app --to-project prod/app --from-token $FROM_TOKEN --to-token $TO_TOKEN --prune --yes
+  gitlab-fork-cli mirror --manifest mirrors.yaml --manifest-concurrency 8`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 0. --manifest: 批量镜像模式，跳过下方单次镜像的必填参数校验，转而逐条目执行
+		if mirrorManifestPath != "" {
+			runMirrorManifest(cmd, mirrorManifestPath, mirrorManifestConcurrency)
+			return
+		}
+
+		// 1. 参数校验，与 clone 命令保持一致的 --xxx-repo-url / --xxx-project 二选一约定
+		if mirrorFromRepoURL != "" && mirrorFromProject != "" {
+			logFatal("❌ 错误: --from-repo-url 与 --from-project 只能提供一个。")
+		}
+		if mirrorFromRepoURL == "" && mirrorFromProject == "" {
+			logFatal("必须提供 --from-repo-url 或 --from-project 中的一个。")
+		}
+		if mirrorFromProject != "" {
+			mirrorFromRepoURL = buildRepoURLFromProject(baseURL, mirrorFromProject)
+		}
+		if mirrorToRepoURL != "" && mirrorToProject != "" {
+			logFatal("❌ 错误: --to-repo-url 与 --to-project 只能提供一个。")
+		}
+		if mirrorToRepoURL == "" && mirrorToProject == "" {
+			logFatal("必须提供 --to-repo-url 或 --to-project 中的一个。")
+		}
+		if mirrorToProject != "" {
+			mirrorToRepoURL = buildRepoURLFromProject(baseURL, mirrorToProject)
+		}
+		if mirrorPrune && !mirrorYes {
+			logFatal("❌ 错误: --prune 会删除目标仓库上源仓库已不存在的引用，必须同时提供 --yes 以确认。")
+		}
+		if mirrorOutputDir == "" {
+			source := rand.NewSource(time.Now().UnixNano())
+			r := rand.New(source)
+			mirrorOutputDir = filepath.Join(os.TempDir(), "gitlab-fork-cli-mirror-"+strconv.Itoa(r.Intn(100000)))
+			log.Printf("未指定 --output-dir，将使用随机临时目录: %s", mirrorOutputDir)
+		}
+
+		// 2. 解析令牌，复用 clone 命令的 "显式标志 > 令牌文件 > 环境变量 > 交互式输入" 优先级链
+		resolvedFromToken, err := resolveCloneToken(mirrorFromToken, mirrorFromTokenFile, gitlabFromTokenEnvVar, "源仓库令牌 (--from-token)", mirrorPromptToken)
+		if err != nil {
+			logFatalf("❌ %v\n", err)
+		}
+		mirrorFromToken = resolvedFromToken
+		var fromAuth pkg.GitAuthMethod
+		if mirrorFromToken != "" {
+			fromAuth = &pkg.BasicAuthMethod{Username: "oauth2", Password: mirrorFromToken}
+		}
+
+		resolvedToToken, err := resolveCloneToken(mirrorToToken, mirrorToTokenFile, gitlabToTokenEnvVar, "目的仓库令牌 (--to-token)", mirrorPromptToken)
+		if err != nil {
+			logFatalf("❌ %v\n", err)
+		}
+		mirrorToToken = resolvedToToken
+		var toAuth pkg.GitAuthMethod
+		if mirrorToToken != "" {
+			toAuth = &pkg.BasicAuthMethod{Username: "oauth2", Password: mirrorToToken}
+		}
+
+		if readOnlyGuard(fmt.Sprintf("将 '%s' 完整镜像到 '%s' (force=%t, prune=%t)", mirrorFromRepoURL, mirrorToRepoURL, mirrorForce, mirrorPrune)) {
+			return
+		}
+
+		warnings := pkg.NewWarningCollector()
+		warnings.SetStrict(strictMode)
+		warnings.SetCorrelationID(correlationID)
+		defer warnings.PrintSummary()
+		timeline := pkg.NewTimeline()
+		if verbose {
+			defer timeline.PrintSummary()
+		}
+
+		ctx := cmd.Context()
+		log.Printf("🚀 正在将 '%s' 完整镜像到 '%s' (force=%t, prune=%t)...\n", mirrorFromRepoURL, mirrorToRepoURL, mirrorForce, mirrorPrune)
+		err = pkg.SyncFork(ctx, pkg.ForkSyncOptions{
+			UpstreamRepoURL: mirrorFromRepoURL,
+			UpstreamAuth:    fromAuth,
+			ForkRepoURL:     mirrorToRepoURL,
+			ForkAuth:        toAuth,
+			OutputDir:       mirrorOutputDir,
+			Force:           mirrorForce,
+			Atomic:          mirrorAtomic,
+			Prune:           mirrorPrune,
+			InsecureSkipTLS: insecureSkip,
+			CACertFile:      caCertFile,
+			ProgressWriter:  os.Stdout,
+			Warnings:        warnings,
+			Timeline:        timeline,
+		})
+		if err != nil {
+			logFatalf("❌ 镜像失败: %v\n", err)
+		}
+
+		log.Println("🎉 镜像完成。")
+	},
+}
+
+func init() {
+	mirrorCmd.Flags().StringVar(&mirrorFromRepoURL, "from-repo-url", "", "源 Git 仓库的完整 URL (与 --from-project 二选一，必填其一)")
+	mirrorCmd.Flags().StringVar(&mirrorFromProject, "from-project", "", "源项目路径 (如 'group/project')，由工具拼接 --base-url 得到完整 URL (与 --from-repo-url 二选一)")
+	mirrorCmd.Flags().StringVar(&mirrorFromToken, "from-token", "", "源仓库用于认证的个人访问令牌 (可选，未提供时依次尝试 --from-token-file、"+gitlabFromTokenEnvVar+" 环境变量、--prompt-token)")
+	mirrorCmd.Flags().StringVar(&mirrorFromTokenFile, "from-token-file", "", "从文件读取源仓库令牌 (可选，优先于 "+gitlabFromTokenEnvVar+" 环境变量与 --prompt-token)")
+	mirrorCmd.Flags().StringVar(&mirrorToRepoURL, "to-repo-url", "", "目的 Git 仓库的完整 URL (与 --to-project 二选一，必填其一)")
+	mirrorCmd.Flags().StringVar(&mirrorToProject, "to-project", "", "目的项目路径 (如 'group/project')，由工具拼接 --base-url 得到完整 URL (与 --to-repo-url 二选一)")
+	mirrorCmd.Flags().StringVar(&mirrorToToken, "to-token", "", "目的仓库用于认证的个人访问令牌 (可选，未提供时依次尝试 --to-token-file、"+gitlabToTokenEnvVar+" 环境变量、--prompt-token)")
+	mirrorCmd.Flags().StringVar(&mirrorToTokenFile, "to-token-file", "", "从文件读取目的仓库令牌 (可选，优先于 "+gitlabToTokenEnvVar+" 环境变量与 --prompt-token)")
+	mirrorCmd.Flags().BoolVar(&mirrorPromptToken, "prompt-token", false, "任一令牌未通过标志/文件/环境变量提供时，交互式从终端读取 (不回显)")
+	mirrorCmd.Flags().StringVar(&mirrorOutputDir, "output-dir", "", "镜像克隆到的本地目录 (可选，默认为临时目录)")
+	mirrorCmd.Flags().BoolVar(&mirrorForce, "force", false, "强制推送，覆盖目标仓库上与源仓库存在分歧 (非快进) 的分支/标签 (⚠️ 会丢弃目标仓库上的独立提交)")
+	mirrorCmd.Flags().BoolVar(&mirrorAtomic, "atomic", false, "以原子方式推送所有分支与标签：任意一个被目标仓库的推送规则拒绝，则整体回滚，避免只有部分分支/标签同步成功的半镜像状态")
+	mirrorCmd.Flags().BoolVar(&mirrorPrune, "prune", false, "删除目标仓库上源仓库已不存在的分支/标签，实现完整的 git --mirror 语义 (⚠️ 会删除目标仓库中源仓库没有的引用，必须同时提供 --yes)")
+	mirrorCmd.Flags().BoolVar(&mirrorYes, "yes", false, "与 --prune 搭配使用，显式确认执行会删除引用的剪枝操作")
+	mirrorCmd.Flags().StringVar(&mirrorManifestPath, "manifest", "", "批量镜像模式：从 YAML 来源读取一组 {from-repo-url|from-project, to-repo-url|to-project} 条目并逐个执行，与 --from-*/--to-* 互斥；其余标志对每个条目统一生效。来源类型与 'fork --manifest' 相同 (本地文件路径、'-'、'http(s)://' URL、'configmap://<namespace>/<name>/<key>')")
+	mirrorCmd.Flags().IntVar(&mirrorManifestConcurrency, "manifest-concurrency", 4, "与 --manifest 搭配使用，批量镜像的并发度")
+
+	for _, name := range []string{"from-token", "to-token", "from-token-file", "to-token-file", "prompt-token"} {
+		categorizeFlag(mirrorCmd, name, "auth")
+	}
+	for _, name := range []string{"from-project", "to-project", "force", "atomic", "prune", "yes", "manifest", "manifest-concurrency"} {
+		categorizeFlag(mirrorCmd, name, "behavior")
+	}
+	categorizeFlag(mirrorCmd, "output-dir", "output")
+
+	rootCmd.AddCommand(mirrorCmd)
+}
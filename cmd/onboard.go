@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg"
+	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
+	"github.com/spf13/cobra"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// 定义 onboard 命令的参数变量
+var (
+	onboardNamespace   string
+	onboardBotUsername string
+	onboardGroupRole   string
+)
+
+// onboardedAnnotation 记录在租户命名空间上，标记该租户已完成过 onboarding
+const onboardedAnnotation = "gitlab-fork-cli/onboarded"
+
+// groupAccessLevelByName 将角色名称映射为 GitLab 的 AccessLevelValue，与 fork.go 中
+// 字符串枚举风格的标志 (如 --on-name-conflict) 保持一致，便于命令行使用。
+var groupAccessLevelByName = map[string]gitlab.AccessLevelValue{
+	"guest":      gitlab.GuestPermissions,
+	"reporter":   gitlab.ReporterPermissions,
+	"developer":  gitlab.DeveloperPermissions,
+	"maintainer": gitlab.MaintainerPermissions,
+	"owner":      gitlab.OwnerPermissions,
+}
+
+// ensureGitLabGroup 确保 path 对应的组存在：已存在则直接返回，不存在则以 name 为名称、
+// parentID 为父组 (顶层组传 nil) 创建。
+func ensureGitLabGroup(client *gitlab.Client, path, name string, parentID *int) (*gitlab.Group, error) {
+	group, resp, err := client.Groups.GetGroup(path, nil)
+	if err == nil {
+		return group, nil
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		return nil, fmt.Errorf("查询组 '%s' 失败: %w", path, err)
+	}
+
+	createOptions := &gitlab.CreateGroupOptions{
+		Name: gitlab.Ptr(name),
+		Path: gitlab.Ptr(name),
+	}
+	if parentID != nil {
+		createOptions.ParentID = parentID
+	}
+	group, _, err = client.Groups.CreateGroup(createOptions)
+	if err != nil {
+		return nil, fmt.Errorf("创建组 '%s' 失败: %w", path, err)
+	}
+	return group, nil
+}
+
+// onboardCmd 定义了 'onboard' 子命令，将新租户接入所需的四个手工步骤 (创建 GitLab 组/子组、
+// 签发令牌并写入 Secret、授予 bot 用户组权限) 合并为一次调用。
+var onboardCmd = &cobra.Command{
+	Use:   "onboard",
+	Short: "为新租户命名空间引导 GitLab 组、令牌 Secret 与 bot 成员身份",
+	Long: `此命令将租户接入过程中分散在多个系统的手工步骤合并为一次调用：
+确保 GitLab 组及其 amlmodels 子组存在、确保命名空间下存在可用的令牌 Secret (缺失时签发一个新的组访问令牌)、
+将租户的 bot 用户以指定角色加入组，并在命名空间上记录 onboarding 完成状态。
+命名空间本身需已存在 (由平台的租户创建流程负责)，本命令不会创建 Kubernetes 命名空间。`,
+	Example: `  gitlab-fork-cli onboard --namespace fy-dev --bot-user fy-dev-bot
+  gitlab-fork-cli onboard --namespace fy-prod --bot-user fy-prod-bot --group-role maintainer`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if onboardNamespace == "" {
+			logFatal("❌ 错误: 缺少必要的命令行参数 (--namespace)。")
+		}
+		accessLevel, ok := groupAccessLevelByName[strings.ToLower(onboardGroupRole)]
+		if !ok {
+			logFatalf("❌ 无效的 --group-role '%s'，可选值: guest, reporter, developer, maintainer, owner。\n", onboardGroupRole)
+		}
+
+		warnings := pkg.NewWarningCollector()
+		warnings.SetStrict(strictMode)
+		warnings.SetCorrelationID(correlationID)
+		defer warnings.PrintSummary()
+
+		ctx := cmd.Context()
+		kubeRestConfig, err := k8sutil.GetKubeConfig()
+		if err != nil {
+			logFatalf("❌ 无法获取 Kubernetes 配置: %v\n", err)
+		}
+
+		// 1. 命名空间代表租户本身，由平台的租户创建流程负责，这里只校验其存在
+		log.Printf("ℹ️ 正在检查命名空间 '%s' 是否存在...\n", onboardNamespace)
+		nsExists, err := k8sutil.CheckK8sNamespaceExists(ctx, kubeRestConfig, onboardNamespace)
+		if err != nil {
+			logFatalf("❌ 检查命名空间失败: %v\n", err)
+		}
+		if !nsExists {
+			logFatalf("❌ 命名空间 '%s' 不存在，请先完成租户命名空间的创建，再执行 onboard。\n", onboardNamespace)
+		}
+
+		adminToken, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, "kubeflow", GitlabSecretName, GitlabTokenKey)
+		if err != nil {
+			logFatalf("❌ 无法获取管理员令牌: %v\n", err)
+		}
+		adminGit, err := newGitLabClient(adminToken, baseURL, insecureSkip, caCertFile)
+		if err != nil {
+			logFatalf("❌ %v\n", err)
+		}
+
+		if readOnlyGuard(fmt.Sprintf("确保 GitLab 组 '%s'/'%s' 存在、必要时签发访问令牌、将 bot 用户 '%s' 加入组、记录 onboarding 状态",
+			onboardNamespace, getModelGroupByNs(onboardNamespace), onboardBotUsername)) {
+			return
+		}
+
+		// 2. 确保 GitLab 组及其 amlmodels 子组存在
+		log.Printf("🚀 正在确保 GitLab 组 '%s' 存在...\n", onboardNamespace)
+		group, err := ensureGitLabGroup(adminGit, onboardNamespace, onboardNamespace, nil)
+		if err != nil {
+			logFatalf("❌ %v\n", err)
+		}
+		log.Printf("✅ 组 '%s' 就绪 (ID: %d)。\n", group.FullPath, group.ID)
+
+		log.Printf("🚀 正在确保子组 '%s' 存在...\n", getModelGroupByNs(onboardNamespace))
+		subGroup, err := ensureGitLabGroup(adminGit, getModelGroupByNs(onboardNamespace), amlModelsGroup, gitlab.Ptr(group.ID))
+		if err != nil {
+			logFatalf("❌ %v\n", err)
+		}
+		log.Printf("✅ 子组 '%s' 就绪 (ID: %d)。\n", subGroup.FullPath, subGroup.ID)
+
+		// 3. 确保命名空间下存在可用令牌；缺失时为新建的组签发一个组访问令牌并写入 Secret
+		existingToken, tokenErr := k8sutil.GetSecretValue(ctx, kubeRestConfig, onboardNamespace, GitlabSecretName, GitlabTokenKey)
+		if tokenErr != nil || existingToken == "" {
+			log.Printf("ℹ️ 命名空间 '%s' 下暂无可用令牌，正在为组 '%s' 签发访问令牌...\n", onboardNamespace, group.FullPath)
+			accessToken, _, err := adminGit.GroupAccessTokens.CreateGroupAccessToken(group.ID, &gitlab.CreateGroupAccessTokenOptions{
+				Name:        gitlab.Ptr(fmt.Sprintf("gitlab-fork-cli-%s", onboardNamespace)),
+				Scopes:      gitlab.Ptr([]string{"api"}),
+				AccessLevel: gitlab.Ptr(gitlab.MaintainerPermissions),
+			})
+			if err != nil {
+				logFatalf("❌ 为组 '%s' 签发访问令牌失败: %v\n", group.FullPath, err)
+			}
+			if _, err := k8sutil.EnsureSecret(ctx, kubeRestConfig, onboardNamespace, GitlabSecretName, GitlabTokenKey, accessToken.Token); err != nil {
+				logFatalf("❌ 写入令牌 Secret 失败: %v\n", err)
+			}
+		} else {
+			log.Printf("✅ 命名空间 '%s' 下已存在可用令牌，跳过签发。\n", onboardNamespace)
+		}
+
+		// 4. 授予命名空间对应的 bot 用户在组上的角色
+		if onboardBotUsername != "" {
+			log.Printf("🚀 正在将用户 '%s' 以 '%s' 角色加入组 '%s'...\n", onboardBotUsername, onboardGroupRole, group.FullPath)
+			_, _, err := adminGit.GroupMembers.AddGroupMember(group.ID, &gitlab.AddGroupMemberOptions{
+				Username:    gitlab.Ptr(onboardBotUsername),
+				AccessLevel: gitlab.Ptr(accessLevel),
+			})
+			if err != nil {
+				if strings.Contains(err.Error(), "already a member") {
+					log.Printf("ℹ️ 用户 '%s' 已是组 '%s' 的成员，跳过。\n", onboardBotUsername, group.FullPath)
+				} else if warnErr := warnings.Add("bot-membership-failed", "将用户 '%s' 加入组 '%s' 失败: %v", onboardBotUsername, group.FullPath, err); warnErr != nil {
+					logFatalf("❌ %v", warnErr)
+				} else {
+					log.Printf("⚠️ 授予 bot 用户组成员身份失败，已记录为警告: %v\n", err)
+				}
+			} else {
+				log.Println("✅ 已授予 bot 用户组成员身份。")
+			}
+		}
+
+		// 5. 记录本次 onboarding 完成情况，便于后续排查与审计
+		if err := k8sutil.SetNamespaceAnnotation(ctx, kubeRestConfig, onboardNamespace, onboardedAnnotation, "true"); err != nil {
+			log.Printf("⚠️ 记录 onboarding 状态失败: %v\n", err)
+		}
+
+		log.Println("\n🎉 租户 onboarding 完成。")
+	},
+}
+
+func init() {
+	onboardCmd.Flags().StringVar(&onboardNamespace, "namespace", "", "待接入的租户命名空间 (必填)")
+	onboardCmd.Flags().StringVar(&onboardBotUsername, "bot-user", "", "租户 bot 用户的 GitLab 用户名，用于授予组权限 (留空则跳过成员授权步骤)")
+	onboardCmd.Flags().StringVar(&onboardGroupRole, "group-role", "maintainer", "授予 bot 用户的组角色：guest, reporter, developer, maintainer, owner")
+
+	for _, name := range []string{"bot-user", "group-role"} {
+		categorizeFlag(onboardCmd, name, "behavior")
+	}
+
+	onboardCmd.MarkFlagRequired("namespace")
+
+	rootCmd.AddCommand(onboardCmd)
+}
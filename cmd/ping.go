@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// 定义 ping 命令的参数变量
+var (
+	pingToken      string
+	pingSampleRepo string
+)
+
+// pingCmd 定义了 'ping' 子命令
+var pingCmd = &cobra.Command{
+	Use:   "ping",
+	Short: "对 GitLab 实例做一次健康探测，在大批量派生前快速给出 go/no-go 判断",
+	Long: `此命令测量 GitLab API 延迟、检查实例版本、验证令牌是否具备派生所需的基本权限，
+并可选地确认指定样例仓库的 git smart-HTTP 端点可正常响应，
+用于在开始大批量派生/推广窗口前快速确认 GitLab 实例是否健康。`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if pingToken == "" {
+			logFatal("❌ 错误: 必须提供 --token 参数 (用于探测的 GitLab 个人访问令牌)。")
+		}
+		if baseURL == "" {
+			logFatal("❌ 错误: 必须提供 --base-url 参数。")
+		}
+
+		ok := true
+
+		// 1. API 延迟与版本检查
+		git, err := newGitLabClient(pingToken, baseURL, insecureSkip, caCertFile)
+		if err != nil {
+			logFatalf("❌ 创建 GitLab 客户端失败: %v\n", err)
+		}
+
+		start := time.Now()
+		version, _, err := git.Version.GetVersion()
+		latency := time.Since(start)
+		if err != nil {
+			log.Printf("❌ 无法获取 GitLab 版本信息，实例可能不可达或令牌无效: %v\n", err)
+			ok = false
+		} else {
+			log.Printf("✅ GitLab API 可达，延迟 %v，版本: %s (revision: %s)\n", latency.Round(time.Millisecond), version.Version, version.Revision)
+		}
+
+		// 2. 派生所需的基本权限检查：确认令牌能够枚举 namespace (派生目标组解析所必需)
+		if _, _, err := git.Namespaces.ListNamespaces(&gitlab.ListNamespacesOptions{}); err != nil {
+			log.Printf("❌ 令牌无法枚举 namespace，派生所需的基本权限可能缺失: %v\n", err)
+			ok = false
+		} else {
+			log.Println("✅ 令牌具备枚举 namespace 的基本权限。")
+		}
+
+		// 3. 样例仓库 smart-HTTP 端点探测 (可选)
+		if pingSampleRepo != "" {
+			httpClient := &http.Client{Timeout: 10 * time.Second}
+			url := fmt.Sprintf("%s/%s.git/info/refs?service=git-upload-pack", baseURL, pingSampleRepo)
+			resp, err := httpClient.Get(url)
+			if err != nil {
+				log.Printf("❌ 样例仓库 '%s' 的 smart-HTTP 端点探测失败: %v\n", pingSampleRepo, err)
+				ok = false
+			} else {
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					log.Printf("❌ 样例仓库 '%s' 的 smart-HTTP 端点返回非预期状态码: %d\n", pingSampleRepo, resp.StatusCode)
+					ok = false
+				} else {
+					log.Printf("✅ 样例仓库 '%s' 的 smart-HTTP 端点响应正常。\n", pingSampleRepo)
+				}
+			}
+		}
+
+		if ok {
+			log.Println("🎉 go/no-go 判断: GO，可以开始批量操作。")
+		} else {
+			logFatal("❌ go/no-go 判断: NO-GO，请先排查上述问题。")
+		}
+	},
+}
+
+func init() {
+	pingCmd.Flags().StringVar(&pingToken, "token", "", "用于探测的 GitLab 个人访问令牌 (必填)")
+	pingCmd.Flags().StringVar(&pingSampleRepo, "sample-repo", "", "可选: 用于验证 git smart-HTTP 端点的样例仓库全路径 (如 my-group/my-project)")
+
+	pingCmd.MarkFlagRequired("token")
+
+	rootCmd.AddCommand(pingCmd)
+}
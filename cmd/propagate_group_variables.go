@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg"
+	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
+	"github.com/spf13/cobra"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// 定义 propagate-group-variables 命令的参数变量
+var (
+	propagateFromGroup string
+	propagateToGroup   string
+	propagateVariables []string
+)
+
+// propagateGroupVariablesCmd 定义了 'propagate-group-variables' 子命令
+var propagateGroupVariablesCmd = &cobra.Command{
+	Use:   "propagate-group-variables",
+	Short: "同步一组白名单内的组级 CI/CD 变量到目标组",
+	Long: `此命令将来源组下一组白名单内的组级 CI/CD 变量同步到目标组，
+保留变量的 masked、protected、variable_type 与 environment_scope 属性。
+与逐项目变量拷贝互补，用于批量派生前先对齐组级配置。
+
+例如:
+  gitlab-fork-cli propagate-group-variables --from-group dev --to-group my-prod --variable REGISTRY_URL --variable BUILD_ENV`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if propagateFromGroup == "" || propagateToGroup == "" {
+			logFatal("❌ 错误: 必须同时提供 --from-group 和 --to-group 参数。")
+		}
+		if len(propagateVariables) == 0 {
+			logFatal("❌ 错误: 必须通过 --variable 至少指定一个要同步的变量名 (白名单)，避免误将全部变量批量同步。")
+		}
+
+		warnings := pkg.NewWarningCollector()
+		warnings.SetStrict(strictMode)
+		warnings.SetCorrelationID(correlationID)
+		defer warnings.PrintSummary()
+		if insecureSkip {
+			if err := warnings.Add("insecure-tls", "已通过 --insecure 关闭 TLS 证书校验"); err != nil {
+				logFatalf("❌ %v", err)
+			}
+		}
+
+		ctx := cmd.Context()
+		log.Println("ℹ️ 正在获取 Kubernetes 配置...")
+		kubeRestConfig, err := k8sutil.GetKubeConfig()
+		if err != nil {
+			logFatalf("❌ 无法获取 Kubernetes 配置，无法获取 Secret。错误: %v\n", err)
+		}
+
+		devToken, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, propagateFromGroup, GitlabSecretName, GitlabTokenKey)
+		if err != nil {
+			logFatalf("❌ 无法获取来源组 '%s' 的令牌。错误: %v\n", propagateFromGroup, err)
+		}
+		devGit, err := newGitLabClient(devToken, baseURL, insecureSkip, caCertFile)
+		if err != nil {
+			logFatalf("❌ 创建来源组 GitLab 客户端失败: %v\n", err)
+		}
+
+		prodToken, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, propagateToGroup, GitlabSecretName, GitlabTokenKey)
+		if err != nil {
+			logFatalf("❌ 无法获取目标组 '%s' 的令牌。错误: %v\n", propagateToGroup, err)
+		}
+		prodGit, err := newGitLabClient(prodToken, baseURL, insecureSkip, caCertFile)
+		if err != nil {
+			logFatalf("❌ 创建目标组 GitLab 客户端失败: %v\n", err)
+		}
+
+		sourceGroupPath := getModelGroupByNs(propagateFromGroup)
+		targetGroupPath := getModelGroupByNs(propagateToGroup)
+
+		synced, skipped := 0, 0
+		for _, key := range propagateVariables {
+			variable, _, err := devGit.GroupVariables.GetVariable(sourceGroupPath, key, nil)
+			if err != nil {
+				if warnErr := warnings.Add("variable-not-found", "来源组 '%s' 中不存在变量 '%s'，已跳过", propagateFromGroup, key); warnErr != nil {
+					logFatalf("❌ %v", warnErr)
+				}
+				log.Printf("⚠️ 来源组 '%s' 中不存在变量 '%s'，已跳过。\n", propagateFromGroup, key)
+				skipped++
+				continue
+			}
+
+			if readOnlyGuard(fmt.Sprintf("将变量 '%s' 同步到目标组 '%s'", key, targetGroupPath)) {
+				continue
+			}
+
+			_, _, err = prodGit.GroupVariables.CreateVariable(targetGroupPath, &gitlab.CreateGroupVariableOptions{
+				Key:              gitlab.Ptr(variable.Key),
+				Value:            gitlab.Ptr(variable.Value),
+				Description:      gitlab.Ptr(variable.Description),
+				EnvironmentScope: gitlab.Ptr(variable.EnvironmentScope),
+				Masked:           gitlab.Ptr(variable.Masked),
+				Protected:        gitlab.Ptr(variable.Protected),
+				Raw:              gitlab.Ptr(variable.Raw),
+				VariableType:     gitlab.Ptr(variable.VariableType),
+			})
+			if err != nil {
+				if warnErr := warnings.Add("variable-sync-failed", "同步变量 '%s' 到目标组 '%s' 失败: %v", key, targetGroupPath, err); warnErr != nil {
+					logFatalf("❌ %v", warnErr)
+				}
+				log.Printf("⚠️ 同步变量 '%s' 失败: %v\n", key, err)
+				skipped++
+				continue
+			}
+			log.Printf("✅ 已同步变量 '%s' 到目标组 '%s'。\n", key, targetGroupPath)
+			synced++
+		}
+
+		log.Printf("🎉 操作完成，成功同步 %d 个变量，跳过 %d 个。\n", synced, skipped)
+	},
+}
+
+func init() {
+	propagateGroupVariablesCmd.Flags().StringVar(&propagateFromGroup, "from-group", "", "来源组的 NS 名称 (必填)")
+	propagateGroupVariablesCmd.Flags().StringVar(&propagateToGroup, "to-group", "", "目标组的 NS 名称 (必填)")
+	propagateGroupVariablesCmd.Flags().StringSliceVar(&propagateVariables, "variable", nil, "要同步的变量名，可重复指定或用逗号分隔 (白名单，必填)")
+
+	propagateGroupVariablesCmd.MarkFlagRequired("from-group")
+	propagateGroupVariablesCmd.MarkFlagRequired("to-group")
+	propagateGroupVariablesCmd.MarkFlagRequired("variable")
+
+	rootCmd.AddCommand(propagateGroupVariablesCmd)
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
+	"github.com/spf13/cobra"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// projectPathAnnotation 是记录在 k8s 命名空间上的、指向该命名空间对应 GitLab 项目当前路径的 annotation key
+const projectPathAnnotation = "gitlab-fork-cli/project-path"
+
+// 定义 rename-project 命令的参数变量
+var (
+	renameGroup   string
+	renameProject string
+	renameNewName string
+	renameNewPath string
+)
+
+// renameProjectCmd 定义了 'rename-project' 子命令
+var renameProjectCmd = &cobra.Command{
+	Use:   "rename-project",
+	Short: "重命名/移动 GitLab 组下的一个项目",
+	Long: `此命令用于纠正一次错误派生后的项目命名，而无需 GitLab UI 的 Maintainer 权限。
+重命名成功后，会同步更新对应命名空间上记录的 '` + projectPathAnnotation + `' annotation，
+避免 k8s 侧记录的项目路径与 GitLab 实际路径产生漂移。`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if renameGroup == "" || renameProject == "" {
+			logFatal("❌ 错误: 必须提供 --group 和 --project 参数。")
+		}
+		if renameNewName == "" && renameNewPath == "" {
+			logFatal("❌ 错误: 必须至少提供 --new-name 或 --new-path 中的一个。")
+		}
+
+		ctx := cmd.Context()
+		kubeRestConfig, err := k8sutil.GetKubeConfig()
+		if err != nil {
+			logFatalf("❌ 无法获取 Kubernetes 配置。错误: %v\n", err)
+		}
+
+		token, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, renameGroup, GitlabSecretName, GitlabTokenKey)
+		if err != nil {
+			logFatalf("❌ 无法获取组 '%s' 的令牌。错误: %v\n", renameGroup, err)
+		}
+		git, err := newGitLabClient(token, baseURL, insecureSkip, caCertFile)
+		if err != nil {
+			logFatalf("❌ 创建 GitLab 客户端失败: %v\n", err)
+		}
+
+		groupPath := getModelGroupByNs(renameGroup)
+		projectID, err := findProjectInGroup(git, groupPath, renameProject, groupEnumFilter{includeArchived: true})
+		if err != nil {
+			logFatalf("❌ 在组 '%s' 中未找到项目 '%s'。错误: %v\n", groupPath, renameProject, err)
+		}
+
+		editOptions := &gitlab.EditProjectOptions{}
+		if renameNewName != "" {
+			editOptions.Name = gitlab.Ptr(renameNewName)
+		}
+		if renameNewPath != "" {
+			editOptions.Path = gitlab.Ptr(renameNewPath)
+		}
+
+		if readOnlyGuard(fmt.Sprintf("重命名项目 '%s' (ID: %d) 为 名称='%s'/路径='%s'", renameProject, projectID, renameNewName, renameNewPath)) {
+			return
+		}
+
+		log.Printf("🚀 正在重命名项目 '%s' (ID: %d)...\n", renameProject, projectID)
+		updatedProject, _, err := git.Projects.EditProject(projectID, editOptions)
+		if err != nil {
+			logFatalf("❌ 重命名项目失败: %v\n", err)
+		}
+		log.Printf("✅ 项目已重命名。新名称: %s, 新路径: %s, Web URL: %s\n",
+			updatedProject.Name, updatedProject.PathWithNamespace, updatedProject.WebURL)
+
+		if err := k8sutil.SetNamespaceAnnotation(ctx, kubeRestConfig, renameGroup, projectPathAnnotation, updatedProject.PathWithNamespace); err != nil {
+			log.Printf("⚠️ 项目重命名成功，但更新命名空间 annotation 失败，请手动核对: %v\n", err)
+		}
+
+		log.Println("🎉 操作完成。")
+	},
+}
+
+func init() {
+	renameProjectCmd.Flags().StringVar(&renameGroup, "group", "", "项目所在的 NS 名称 (必填)")
+	renameProjectCmd.Flags().StringVar(&renameProject, "project", "", "要重命名的项目当前名称 (必填)")
+	renameProjectCmd.Flags().StringVar(&renameNewName, "new-name", "", "项目的新显示名称 (可选)")
+	renameProjectCmd.Flags().StringVar(&renameNewPath, "new-path", "", "项目的新路径 (可选)")
+
+	renameProjectCmd.MarkFlagRequired("group")
+	renameProjectCmd.MarkFlagRequired("project")
+
+	rootCmd.AddCommand(renameProjectCmd)
+}
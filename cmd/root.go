@@ -1,18 +1,99 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/fy1316/gitlab-fork-cli/pkg"
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
+// flagCategoryAnnotation 是记录在 pflag.Flag 上的 annotation key，用于将标志分组渲染到帮助信息中
+// (认证 auth / 行为 behavior / 输出 output)，而不是把几十个标志混在一个长列表里。
+const flagCategoryAnnotation = "gitlab-fork-cli/flag-category"
+
+// flagCategories 定义了分组渲染的固定顺序与展示名称，不在此列表中的标志归入"其他标志"
+var flagCategories = []struct {
+	Key   string
+	Label string
+}{
+	{"auth", "认证相关标志"},
+	{"behavior", "行为相关标志"},
+	{"output", "输出相关标志"},
+}
+
+// categorizeFlag 将 cmd 下名为 name 的标志标记为指定分类，供自定义 usage 模板分组展示
+func categorizeFlag(cmd *cobra.Command, name, category string) {
+	f := cmd.Flags().Lookup(name)
+	if f == nil {
+		f = cmd.PersistentFlags().Lookup(name)
+	}
+	if f == nil {
+		return
+	}
+	if f.Annotations == nil {
+		f.Annotations = map[string][]string{}
+	}
+	f.Annotations[flagCategoryAnnotation] = []string{category}
+}
+
+// flagsInCategory 返回 flagSet 中被标记为 category 的标志的 usage 文本
+func flagsInCategory(flagSet *pflag.FlagSet, category string) string {
+	sub := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flagSet.VisitAll(func(f *pflag.Flag) {
+		if cats, ok := f.Annotations[flagCategoryAnnotation]; ok && len(cats) > 0 && cats[0] == category {
+			sub.AddFlag(f)
+		}
+	})
+	return strings.TrimRight(sub.FlagUsages(), "\n")
+}
+
+// uncategorizedFlags 返回 flagSet 中未被分类的标志的 usage 文本
+func uncategorizedFlags(flagSet *pflag.FlagSet) string {
+	sub := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flagSet.VisitAll(func(f *pflag.Flag) {
+		if _, ok := f.Annotations[flagCategoryAnnotation]; !ok {
+			sub.AddFlag(f)
+		}
+	})
+	return strings.TrimRight(sub.FlagUsages(), "\n")
+}
+
 // root 命令的全局变量，可以在子命令中访问
 var (
-	baseURL      string
-	insecureSkip bool
+	baseURL        string
+	insecureSkip   bool
+	strictMode     bool
+	verbose        bool
+	readOnly       bool
+	pushgatewayURL string  // 非空时，运行结束后将本次运行的结果 (成功/失败) 与耗时推送到该 Pushgateway
+	pushgatewayJob string  // 推送到 Pushgateway 时使用的 job 标签，默认为实际执行的子命令名
+	caCertFile     string  // 非空时加载该文件作为附加的 CA 证书，用于校验 GitLab API 与 git 远程的 TLS 证书
+	correlationID  string  // 贯穿本次运行的关联 ID，未显式提供时自动生成，用于跨日志/GitLab/k8s/流水线关联同一次操作
+	maxRPS         float64 // 非零时覆盖 GitLab 客户端根据 RateLimit-Limit 响应头自动推算出的限流速率上限
+	runStartedAt   time.Time
+	currentCmd     *cobra.Command // PersistentPreRun 中记录本次执行的子命令，供 logFatal/logFatalf 在致命错误路径上报 Pushgateway 失败指标
 )
 
+// readOnlyGuard 在 --read-only 模式下拦截即将执行的写操作：打印本应执行的动作后返回 true，
+// 调用方应在返回 true 时跳过实际的写操作 (返回或 continue)，而不是执行它。
+// --read-only 未启用时始终返回 false，不影响正常流程。
+func readOnlyGuard(action string) bool {
+	if !readOnly {
+		return false
+	}
+	log.Printf("🔒 --read-only 已启用，跳过写操作: %s\n", action)
+	return true
+}
+
 // rootCmd 代表了程序的基础命令，所有的子命令都将依附于它
 var rootCmd = &cobra.Command{
 	Use:   "gitlab-fork-cli",
@@ -20,26 +101,142 @@ var rootCmd = &cobra.Command{
 	Long: `gitlab-fork-cli 是一个命令行工具，
 用于自动化从一个 GitLab 组派生项目到另一个 GitLab 组的操作。
 
-例如:
-  gitlab-fork-cli fork --source-group my-dev --source-project my-app --target-group my-prod --dev-token <token1> --prod-token <token2>`,
+开发/生产令牌通过 Kubernetes Secret 自动解析 (以 --source-group/--target-group 对应的命名空间获取)，
+无需在命令行传入令牌。运行 'gitlab-fork-cli <子命令> --help' 查看每个子命令的用法示例。`,
+	Example: `  gitlab-fork-cli fork --source-group my-dev --source-project my-app --target-group my-prod
+  gitlab-fork-cli list-projects --group my-dev --visibility public
+  gitlab-fork-cli ping --token <token> --base-url https://gitlab.example.com`,
+	// 在解析完标志、执行任何子命令之前加载配置文件默认值，命令行参数仍始终优先于配置文件
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		loadGlobalConfigDefaults(cmd)
+		if correlationID == "" {
+			correlationID = uuid.NewString()
+		}
+		log.SetPrefix(fmt.Sprintf("[%s] ", correlationID))
+		runStartedAt = time.Now()
+		currentCmd = cmd
+	},
+	// 子命令的 Run 正常返回即视为成功，推送 outcome=success 的指标。子命令内部以 log.Fatal/log.Fatalf
+	// 报错会直接 os.Exit，不会走到这里——但整个代码库统一改用了下方的 logFatal/logFatalf，
+	// 二者在退出前会先上报 outcome=false，因此失败路径同样会被计入成功率看板。
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		reportRunOutcome(cmd, true)
+	},
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
 }
 
+// reportRunOutcome 在 --pushgateway-url 已配置时，将本次运行的结果与耗时推送到 Pushgateway，
+// 未配置时静默跳过；推送失败仅记录警告，不影响命令本身的退出码。cmd 为 nil (标志解析阶段就失败，
+// PersistentPreRun 从未执行) 时按未知子命令上报。
+func reportRunOutcome(cmd *cobra.Command, success bool) {
+	if pushgatewayURL == "" {
+		return
+	}
+	job := pushgatewayJob
+	if job == "" {
+		if cmd != nil {
+			job = cmd.Name()
+		} else {
+			job = "unknown"
+		}
+	}
+	if err := pkg.PushRunOutcome(pushgatewayURL, job, success, time.Since(runStartedAt)); err != nil {
+		log.Printf("⚠️ 推送运行指标到 Pushgateway 失败: %v\n", err)
+	}
+}
+
+// logFatal/logFatalf 与 log.Fatal/log.Fatalf 行为一致 (打印后以 exit 1 终止进程)，
+// 唯一区别是终止前会先按 outcome=false 上报一次 Pushgateway 指标 (若 --pushgateway-url 已配置)。
+// cobra 的 PersistentPostRun 只在 Run 正常返回时执行，log.Fatal 的 os.Exit 会跳过它，
+// 导致失败的运行在成功率看板上完全不可见；本代码库所有致命错误退出路径统一改用这两个函数替代
+// 直接调用 log.Fatal/log.Fatalf。
+func logFatal(v ...interface{}) {
+	reportRunOutcome(currentCmd, false)
+	log.Fatal(v...)
+}
+
+func logFatalf(format string, v ...interface{}) {
+	reportRunOutcome(currentCmd, false)
+	log.Fatalf(format, v...)
+}
+
 // Execute 为你的 root 命令添加所有子命令，并适当设置标志。
-// 这是从 main() 调用的。
+// 这是从 main() 调用的。收到 SIGINT/SIGTERM 时通过 cmd.Context() 通知各子命令尽快取消正在进行的
+// git/GitLab/k8s 操作并做必要的清理，而不是被硬杀死留下悬空的临时目录或半途而废的写操作。
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "错误: %s\n", err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		reportRunOutcome(currentCmd, false)
+		fmt.Fprintf(os.Stderr, "错误: %s\n", pkg.Redact(err.Error()))
 		os.Exit(1)
 	}
 }
 
+// groupedFlagsUsageTemplate 按 auth/behavior/output 分组渲染标志，未分类的标志归入"其他标志"，
+// 替代 cobra 默认把本地标志与继承标志混在一个长列表里的行为。
+const groupedFlagsUsageTemplate = `Usage:{{if .Runnable}}
+  {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
+  {{.CommandPath}} [command]{{end}}{{if gt (len .Aliases) 0}}
+
+Aliases:
+  {{.NameAndAliases}}{{end}}{{if .HasExample}}
+
+Examples:
+{{.Example}}{{end}}{{if .HasAvailableSubCommands}}
+
+Available Commands:{{range .Commands}}{{if (or .IsAvailableCommand (eq .Name "help"))}}
+  {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableLocalFlags}}
+{{$allFlags := .LocalFlags}}{{range $cat := flagCategories}}{{$text := flagsInCategory $allFlags $cat.Key}}{{if $text}}
+{{$cat.Label}}:
+{{$text}}
+{{end}}{{end}}{{$other := uncategorizedFlags $allFlags}}{{if $other}}
+其他标志:
+{{$other}}
+{{end}}{{end}}{{if .HasAvailableInheritedFlags}}
+Global Flags:
+{{.InheritedFlags.FlagUsages | trimTrailingWhitespaces}}{{end}}{{if .HasHelpSubCommands}}
+
+Additional help topics:{{range .Commands}}{{if .IsAdditionalHelpTopicCommand}}
+  {{rpad .CommandPath .CommandPathPadding}} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableSubCommands}}
+
+Use "{{.CommandPath}} [command] --help" for more information about a command.{{end}}
+`
+
 func init() {
+	// 所有日志行、错误信息与进度输出最终都经由 log 包写出，在此处统一挂载脱敏 writer，
+	// 避免令牌或 URL 内嵌的 basic-auth 凭证随日志泄露 (repo URL 可能内嵌凭证)。
+	log.SetOutput(pkg.NewRedactingWriter(os.Stderr))
+
 	// 定义全局标志 (flag)
 	rootCmd.PersistentFlags().StringVarP(&baseURL, "base-url", "u", "https://aml-gitlab.alaudatech.net", "GitLab API 的基础 URL (e.g., 'https://gitlab.com')")
 	rootCmd.PersistentFlags().BoolVarP(&insecureSkip, "insecure", "k", false, "跳过 TLS 证书验证 (⚠️ 慎用)")
+	rootCmd.PersistentFlags().BoolVar(&strictMode, "strict", false, "严格模式：将 TLS 校验关闭、标签跳过等警告提升为错误，用于要求完全干净运行的监管环境")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "输出详细信息，包括各阶段耗时的操作时间线")
+	rootCmd.PersistentFlags().BoolVar(&readOnly, "read-only", false, "只读模式：任何会修改 GitLab 或 Kubernetes 状态的操作都会被拒绝，仅打印本应执行的操作，便于让新操作者安全地探索本工具")
+	rootCmd.PersistentFlags().StringVar(&globalConfigFilePath, "config", "", "配置来源，用于覆盖 base URL、令牌 Secret 名称/key、amlmodels 子组名称等默认值 (省略时尝试读取 ~/.gitlab-fork-cli.yaml，不存在则静默跳过)。显式提供时除本地文件路径外还支持 '-' (标准输入)、'http(s)://' URL、'configmap://<namespace>/<name>/<key>'")
+	rootCmd.PersistentFlags().StringVar(&pushgatewayURL, "pushgateway-url", "", "Prometheus Pushgateway 的地址 (如 'http://pushgateway:9091')，提供后运行结束时会推送本次结果与耗时，用于一次性 CLI/CronJob 场景下积累成功率看板")
+	rootCmd.PersistentFlags().StringVar(&pushgatewayJob, "pushgateway-job", "", "推送到 Pushgateway 时使用的 job 标签 (可选，默认为实际执行的子命令名)")
+	rootCmd.PersistentFlags().StringVar(&caCertFile, "ca-cert", "", "自定义 CA 证书文件路径，与系统证书池一并用于校验 GitLab API 与 git 远程的 TLS 证书 (内部自签发 CA 场景下无需为此整体启用 --insecure)")
+	rootCmd.PersistentFlags().StringVar(&correlationID, "correlation-id", "", "贯穿本次运行的关联 ID，未提供时自动生成一个 UUID；会附加到日志前缀、GitLab API 请求头 (X-Correlation-ID)、k8s annotation 与运行结束时的汇总报告，便于跨 GitLab 日志/k8s 事件/流水线追踪同一次操作")
+	rootCmd.PersistentFlags().Float64Var(&maxRPS, "max-rps", 0, "覆盖 GitLab 客户端根据 RateLimit-Limit 响应头自动推算出的每秒请求数上限 (0 表示不覆盖，完全依赖 GitLab 返回的 RateLimit-Limit/RateLimit-Reset 响应头自动限流)")
+	categorizeFlag(rootCmd, "insecure", "auth")
+	categorizeFlag(rootCmd, "strict", "behavior")
+	categorizeFlag(rootCmd, "verbose", "output")
+	categorizeFlag(rootCmd, "read-only", "behavior")
+	categorizeFlag(rootCmd, "config", "behavior")
+	categorizeFlag(rootCmd, "pushgateway-url", "output")
+	categorizeFlag(rootCmd, "pushgateway-job", "output")
+	categorizeFlag(rootCmd, "ca-cert", "auth")
+	categorizeFlag(rootCmd, "correlation-id", "output")
+	categorizeFlag(rootCmd, "max-rps", "behavior")
+
+	cobra.AddTemplateFunc("flagCategories", func() any { return flagCategories })
+	cobra.AddTemplateFunc("flagsInCategory", flagsInCategory)
+	cobra.AddTemplateFunc("uncategorizedFlags", uncategorizedFlags)
+	rootCmd.SetUsageTemplate(groupedFlagsUsageTemplate)
 
 	// 注册子命令 (在 fork.go 中定义)
 	rootCmd.AddCommand(forkCmd)
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg"
+	"github.com/spf13/cobra"
+)
+
+// schemaCmd 定义了 'schema' 子命令，打印本工具为 manifest/config 等文件格式发布的
+// 内置 JSON Schema，供使用者提前用通用 JSON Schema 校验器自行检查，或单纯了解字段约束。
+var schemaCmd = &cobra.Command{
+	Use:   "schema [name]",
+	Short: "打印 manifest/config 文件格式的内置 JSON Schema",
+	Long: `此命令打印本工具内置并用于校验 manifest/config 输入的 JSON Schema。
+不带参数时列出所有已发布的 schema 名称；指定名称时打印该 schema 的完整 JSON 内容，
+可通过 shell 重定向保存到文件后交给通用的 JSON Schema 校验工具或编辑器补全使用。`,
+	Example: `  gitlab-fork-cli schema
+  gitlab-fork-cli schema fork-manifest`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println(strings.Join(pkg.SchemaNames(), "\n"))
+			return
+		}
+
+		name := args[0]
+		schemaJSON, ok := pkg.Schemas[name]
+		if !ok {
+			logFatalf("❌ 未知的 schema 名称 '%s'，可用名称: %s\n", name, strings.Join(pkg.SchemaNames(), ", "))
+		}
+		fmt.Println(schemaJSON)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"expvar"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// 定义 serve 命令的参数变量
+var (
+	serveAddr            string
+	serveEnablePprof     bool
+	serveEnableAPI       bool
+	serveAPIToken        string
+	serveAPITokenFile    string
+	serveAPIAllowNoToken bool
+)
+
+// resolveServeAPIToken 解析 --enable-api 对应的共享密钥：--api-token 优先，
+// 其次 --api-token-file；均未提供时，仅当 --api-allow-no-token 显式确认过才返回空字符串
+// (对应不做任何鉴权)，否则报错退出，避免在忘记配置密钥的情况下无意暴露一个可触发任意派生的
+// 匿名 HTTP 端点。
+func resolveServeAPIToken(explicit, tokenFile string, allowNoToken bool) (string, error) {
+	if explicit != "" {
+		return explicit, nil
+	}
+	if tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return "", fmt.Errorf("读取 --api-token-file '%s' 失败: %w", tokenFile, err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	if allowNoToken {
+		return "", nil
+	}
+	return "", fmt.Errorf("--enable-api 需要通过 --api-token 或 --api-token-file 配置共享密钥，否则任何能访问该端口的调用方都能触发派生；" +
+		"确认已通过其他方式 (如网络隔离) 限制访问后，可用 --api-allow-no-token 显式跳过")
+}
+
+// serveCmd 定义了 'serve' 子命令，用于以常驻进程方式运行诊断/服务端点
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "以常驻服务方式运行，暴露诊断端点",
+	Long: `此命令启动一个 HTTP 服务进程。默认只暴露健康检查端点；
+可通过 --enable-pprof 打开 net/http/pprof 与 expvar 端点，用于排查大批量镜像/派生任务中观察到的内存增长问题；
+可通过 --enable-api 打开 POST /api/v1/forks 与 GET /api/v1/forks/{id}，让平台后端通过 HTTP 发起/查询一次
+派生，而不必自行 exec 本工具的二进制文件 (内部仍以子进程方式调用 'fork' 子命令，复用其全部现有校验与流程)。
+--enable-api 要求同时提供 --api-token 或 --api-token-file 配置共享密钥，调用方须在请求头 X-Api-Token
+中携带同样的值 (与 'listen' 校验 X-Gitlab-Token 的模型一致)，否则拒绝启动；确认已通过网络隔离等其他
+手段限制访问后，可用 --api-allow-no-token 显式跳过。
+⚠️ pprof/expvar 会暴露运行时内部信息，--enable-api 会触发实际的派生操作，请勿在没有网络隔离/鉴权的情况下对公网开放。`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("ok"))
+		})
+
+		if serveEnablePprof {
+			log.Println("⚠️ 已启用 pprof/expvar 诊断端点 (/debug/pprof, /debug/vars)")
+			mux.HandleFunc("/debug/pprof/", pprof.Index)
+			mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+			mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+			mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+			mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+			mux.Handle("/debug/vars", expvar.Handler())
+		}
+
+		if serveEnableAPI {
+			apiToken, err := resolveServeAPIToken(serveAPIToken, serveAPITokenFile, serveAPIAllowNoToken)
+			if err != nil {
+				logFatalf("❌ %v\n", err)
+			}
+			if apiToken == "" {
+				log.Println("⚠️ 已启用 REST API 端点且未配置 --api-token/--api-token-file (POST /api/v1/forks, GET /api/v1/forks/{id})，任何能访问该端口的调用方都能触发派生。")
+			} else {
+				log.Println("⚠️ 已启用 REST API 端点 (POST /api/v1/forks, GET /api/v1/forks/{id})，已要求 X-Api-Token 鉴权。")
+			}
+			registerForkAPIRoutes(mux, newForkAPIStore(), apiToken)
+		}
+
+		log.Printf("ℹ️ 服务正在监听 %s\n", serveAddr)
+		if err := http.ListenAndServe(serveAddr, mux); err != nil {
+			logFatalf("❌ 服务启动失败: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "服务监听地址")
+	serveCmd.Flags().BoolVar(&serveEnablePprof, "enable-pprof", false, "暴露 net/http/pprof 与 expvar 诊断端点 (⚠️ 会泄露运行时内部信息)")
+	serveCmd.Flags().BoolVar(&serveEnableAPI, "enable-api", false, "暴露 POST /api/v1/forks 与 GET /api/v1/forks/{id}，以 HTTP 方式发起/查询派生 (⚠️ 会触发实际的派生操作)")
+	serveCmd.Flags().StringVar(&serveAPIToken, "api-token", "", "配合 --enable-api 使用，要求请求头 X-Api-Token 与之相等才能访问 REST API (与 --api-token-file 二选一)")
+	serveCmd.Flags().StringVar(&serveAPITokenFile, "api-token-file", "", "配合 --enable-api 使用，从文件读取 X-Api-Token 校验值 (与 --api-token 二选一)")
+	serveCmd.Flags().BoolVar(&serveAPIAllowNoToken, "api-allow-no-token", false, "配合 --enable-api 使用，显式跳过 --api-token/--api-token-file 校验 (⚠️ REST API 端点将不做任何鉴权)")
+
+	categorizeFlag(serveCmd, "enable-pprof", "behavior")
+	categorizeFlag(serveCmd, "enable-api", "behavior")
+	categorizeFlag(serveCmd, "api-token", "auth")
+	categorizeFlag(serveCmd, "api-token-file", "auth")
+	categorizeFlag(serveCmd, "api-allow-no-token", "auth")
+
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// forkAPIRequest 是 POST /api/v1/forks 请求体，字段对应 'fork' 命令最常用的一组标志；
+// 未覆盖的标志 (如 --wait、--dry-run、--setup-environments 等) 不在此 REST 接口范围内，
+// 需要这些能力的调用方仍应直接使用 CLI。
+type forkAPIRequest struct {
+	SourceGroup       string `json:"source_group"`
+	SourceProject     string `json:"source_project"`
+	TargetGroup       string `json:"target_group"`
+	TargetProjectName string `json:"target_project_name,omitempty"`
+}
+
+// forkAPIJob 记录一次通过 REST API 发起的派生任务的状态。本工具以子进程方式复用现有的
+// 'fork' 单次派生逻辑 (与 --manifest 批量模式重新调用当前可执行文件的策略一致)，
+// 而不是在 HTTP handler 中重新实现一遍令牌解析/跨实例判断等逻辑。
+type forkAPIJob struct {
+	ID        string         `json:"id"`
+	Status    string         `json:"status"` // pending, running, succeeded, failed
+	Request   forkAPIRequest `json:"request"`
+	Output    string         `json:"output,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// forkAPIStore 是进程内的任务状态存储，仅在当前 serve 进程存活期间有效 (重启后任务历史丢失)，
+// 与本工具其余状态一样不依赖外部数据库。
+type forkAPIStore struct {
+	mu   sync.Mutex
+	jobs map[string]*forkAPIJob
+}
+
+func newForkAPIStore() *forkAPIStore {
+	return &forkAPIStore{jobs: make(map[string]*forkAPIJob)}
+}
+
+// newAPIJobID 生成一个随机的 16 位十六进制任务 ID，与 generateWebhookSecret 使用同一种
+// crypto/rand 编码方式。
+func newAPIJobID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成任务 ID 失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (s *forkAPIStore) create(req forkAPIRequest) (*forkAPIJob, error) {
+	id, err := newAPIJobID()
+	if err != nil {
+		return nil, err
+	}
+	job := &forkAPIJob{ID: id, Status: "pending", Request: req, CreatedAt: time.Now()}
+	s.mu.Lock()
+	s.jobs[id] = job
+	s.mu.Unlock()
+	return job, nil
+}
+
+func (s *forkAPIStore) get(id string) (*forkAPIJob, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	return job, ok
+}
+
+func (s *forkAPIStore) update(id string, mutate func(*forkAPIJob)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if job, ok := s.jobs[id]; ok {
+		mutate(job)
+	}
+}
+
+// runForkAPIJob 以子进程方式调用当前可执行文件的 'fork' 子命令 (与批量派生 manifest 相同的
+// 复用策略)，异步执行完成后更新任务状态；子进程输出按任务 ID 加前缀实时打印到服务日志，
+// 便于在并发的多个 API 请求间区分各自的执行过程。
+func runForkAPIJob(store *forkAPIStore, job *forkAPIJob) {
+	store.update(job.ID, func(j *forkAPIJob) { j.Status = "running" })
+
+	exePath, err := os.Executable()
+	if err != nil {
+		store.update(job.ID, func(j *forkAPIJob) {
+			j.Status = "failed"
+			j.Error = fmt.Sprintf("无法定位当前可执行文件: %v", err)
+		})
+		return
+	}
+
+	args := []string{"fork",
+		"--source-group", job.Request.SourceGroup,
+		"--source-project", job.Request.SourceProject,
+		"--target-group", job.Request.TargetGroup,
+	}
+	if job.Request.TargetProjectName != "" {
+		args = append(args, "--target-project-name", job.Request.TargetProjectName)
+	}
+
+	output, err := runPrefixedSubcommand(exePath, args, fmt.Sprintf("api:%s", job.ID))
+	store.update(job.ID, func(j *forkAPIJob) {
+		j.Output = output
+		if err != nil {
+			j.Status = "failed"
+			j.Error = err.Error()
+		} else {
+			j.Status = "succeeded"
+		}
+	})
+}
+
+// registerForkAPIRoutes 在 mux 上挂载 POST /api/v1/forks 与 GET /api/v1/forks/{id}，
+// 让平台后端可以通过 HTTP 发起/查询一次派生，而不必自行 exec 本工具的二进制文件。
+// apiToken 非空时，两个路由均要求请求头 X-Api-Token 与之相等，否则返回 401，与 'listen'
+// 校验 X-Gitlab-Token 的模型一致；apiToken 为空 (--enable-api 未配合 --api-token/--api-token-file
+// 使用) 时不做任何校验，仅供已通过网络隔离等其他手段限制访问的部署使用。
+func registerForkAPIRoutes(mux *http.ServeMux, store *forkAPIStore, apiToken string) {
+	authorized := func(r *http.Request) bool {
+		return apiToken == "" || r.Header.Get("X-Api-Token") == apiToken
+	}
+
+	mux.HandleFunc("POST /api/v1/forks", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) {
+			http.Error(w, `{"error": "invalid or missing X-Api-Token"}`, http.StatusUnauthorized)
+			return
+		}
+
+		var req forkAPIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf(`{"error": "请求体不是合法 JSON: %v"}`, err), http.StatusBadRequest)
+			return
+		}
+		if req.SourceGroup == "" || req.SourceProject == "" || req.TargetGroup == "" {
+			http.Error(w, `{"error": "source_group/source_project/target_group 均为必填"}`, http.StatusBadRequest)
+			return
+		}
+
+		job, err := store.create(req)
+		if err != nil {
+			http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusInternalServerError)
+			return
+		}
+		go runForkAPIJob(store, job)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(job)
+	})
+
+	mux.HandleFunc("GET /api/v1/forks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) {
+			http.Error(w, `{"error": "invalid or missing X-Api-Token"}`, http.StatusUnauthorized)
+			return
+		}
+
+		id := r.PathValue("id")
+		job, ok := store.get(id)
+		if !ok {
+			http.Error(w, `{"error": "未找到该任务 ID"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(job)
+	})
+}
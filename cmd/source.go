@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
+)
+
+// readSource 读取 --manifest/--config 等参数指定的来源内容。除本地文件路径外还支持：
+//   - "-": 从标准输入读取
+//   - "http://"/"https://" 前缀: 从该 URL 读取
+//   - "configmap://<namespace>/<name>/<key>": 从对应命名空间下 ConfigMap 的指定 key 读取
+//
+// 便于 GitOps 系统直接把生成的 manifest/config 通过管道、URL 或已挂载的 ConfigMap 传入，无需先落地临时文件。
+// ctx 用于在 HTTP 请求与 configmap:// 的 k8s API 调用上支持 SIGINT/SIGTERM 时的及时取消。
+func readSource(ctx context.Context, source string) ([]byte, error) {
+	switch {
+	case source == "-":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("从标准输入读取失败: %w", err)
+		}
+		return data, nil
+
+	case strings.HasPrefix(source, "http://"), strings.HasPrefix(source, "https://"):
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+		if err != nil {
+			return nil, fmt.Errorf("构造对 URL '%s' 的请求失败: %w", source, err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("从 URL '%s' 读取失败: %w", source, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("从 URL '%s' 读取失败，HTTP 状态码: %d", source, resp.StatusCode)
+		}
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("读取 URL '%s' 的响应体失败: %w", source, err)
+		}
+		return data, nil
+
+	case strings.HasPrefix(source, "configmap://"):
+		ref := strings.TrimPrefix(source, "configmap://")
+		parts := strings.SplitN(ref, "/", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("configmap:// 来源格式应为 'configmap://<namespace>/<name>/<key>'，实际: %s", source)
+		}
+		kubeRestConfig, err := k8sutil.GetKubeConfig()
+		if err != nil {
+			return nil, fmt.Errorf("无法获取 Kubernetes 配置，无法读取 configmap:// 来源: %w", err)
+		}
+		value, err := k8sutil.GetConfigMapValue(ctx, kubeRestConfig, parts[0], parts[1], parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("读取 ConfigMap '%s/%s' 的 key '%s' 失败: %w", parts[0], parts[1], parts[2], err)
+		}
+		return []byte(value), nil
+
+	default:
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("读取文件 '%s' 失败: %w", source, err)
+		}
+		return data, nil
+	}
+}
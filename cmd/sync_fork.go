@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg"
+	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
+	"github.com/spf13/cobra"
+)
+
+// 定义 sync-fork 命令的参数变量
+var (
+	syncForkGroup         string // fork 项目所在的 NS 名称
+	syncForkProject       string // fork 项目名称
+	syncForkUpstreamGroup string // 上游项目所在的 NS 名称 (可选，省略时从 fork 关系中的路径推断)
+	syncForkForce         bool   // 强制推送，覆盖 fork 上非快进的分支/标签
+	syncForkAtomic        bool   // 以原子方式推送所有分支与标签，任意一个被拒绝则整体回滚
+	syncForkPrune         bool   // 删除 fork 上上游已不存在的分支/标签，实现完整的 --mirror 语义
+	syncForkYes           bool   // 与 --prune 搭配的显式确认，避免误删引用
+	syncForkOutputDir     string // 镜像克隆到的本地目录
+)
+
+// syncForkCmd 定义了 'sync-fork' 子命令
+var syncForkCmd = &cobra.Command{
+	Use:   "sync-fork",
+	Short: "将已派生的 fork 项目从其上游同步到最新状态",
+	Long: `此命令通过 GitLab 的 fork 关系找到 fork 项目对应的上游项目，
+镜像克隆上游的全部分支与标签，并推送 (默认要求快进，--force 时强制覆盖) 到 fork 项目，
+用于让长期存在的生产 fork 追平已经领先的上游 dev 项目，而无需逐个分支/标签手动操作。`,
+	Example: `  gitlab-fork-cli sync-fork --group my-prod --project my-app
+  gitlab-fork-cli sync-fork --group my-prod --project my-app --force`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if syncForkGroup == "" || syncForkProject == "" {
+			logFatal("❌ 错误: 必须提供 --group 和 --project 参数。")
+		}
+		if syncForkPrune && !syncForkYes {
+			logFatal("❌ 错误: --prune 会删除 fork 上游已不存在的引用，必须同时提供 --yes 以确认。")
+		}
+
+		warnings := pkg.NewWarningCollector()
+		warnings.SetStrict(strictMode)
+		warnings.SetCorrelationID(correlationID)
+		defer warnings.PrintSummary()
+		if insecureSkip {
+			if err := warnings.Add("insecure-tls", "已通过 --insecure 关闭 TLS 证书校验"); err != nil {
+				logFatalf("❌ %v", err)
+			}
+		}
+		timeline := pkg.NewTimeline()
+		if verbose {
+			defer timeline.PrintSummary()
+		}
+
+		ctx := cmd.Context()
+		kubeRestConfig, err := k8sutil.GetKubeConfig()
+		if err != nil {
+			logFatalf("❌ 无法获取 Kubernetes 配置。错误: %v\n", err)
+		}
+
+		forkToken, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, syncForkGroup, GitlabSecretName, GitlabTokenKey)
+		if err != nil {
+			logFatalf("❌ 无法获取组 '%s' 的令牌。错误: %v\n", syncForkGroup, err)
+		}
+		forkGit, err := newGitLabClient(forkToken, baseURL, insecureSkip, caCertFile)
+		if err != nil {
+			logFatalf("❌ 创建 GitLab 客户端失败: %v\n", err)
+		}
+
+		groupPath := getModelGroupByNs(syncForkGroup)
+		forkProjectID, err := findProjectInGroup(forkGit, groupPath, syncForkProject, groupEnumFilter{includeArchived: true})
+		if err != nil {
+			logFatalf("❌ 在组 '%s' 中未找到项目 '%s'。错误: %v\n", groupPath, syncForkProject, err)
+		}
+		forkProject, _, err := forkGit.Projects.GetProject(forkProjectID, nil)
+		if err != nil {
+			logFatalf("❌ 获取 fork 项目 '%s' 的详情失败: %v\n", syncForkProject, err)
+		}
+		if forkProject.ForkedFromProject == nil {
+			logFatalf("❌ 项目 '%s' 不是通过派生创建的 (没有 fork 关系)，无法同步。\n", forkProject.PathWithNamespace)
+		}
+		upstream := forkProject.ForkedFromProject
+
+		upstreamGroup := syncForkUpstreamGroup
+		if upstreamGroup == "" {
+			upstreamGroup = strings.SplitN(upstream.PathWithNamespace, "/", 2)[0]
+			log.Printf("ℹ️ 未指定 --upstream-group，从上游路径 '%s' 推断其所在 NS 为 '%s'。\n", upstream.PathWithNamespace, upstreamGroup)
+		}
+		upstreamToken, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, upstreamGroup, GitlabSecretName, GitlabTokenKey)
+		if err != nil {
+			logFatalf("❌ 无法获取上游项目所在组 '%s' 的令牌。错误: %v\n", upstreamGroup, err)
+		}
+
+		if readOnlyGuard(fmt.Sprintf("将 fork 项目 '%s' 从上游 '%s' 同步 (force=%t)", forkProject.PathWithNamespace, upstream.PathWithNamespace, syncForkForce)) {
+			return
+		}
+
+		outputDir := syncForkOutputDir
+		if outputDir == "" {
+			source := rand.NewSource(time.Now().UnixNano())
+			r := rand.New(source)
+			outputDir = filepath.Join(os.TempDir(), "gitlab-fork-cli-sync-fork-"+strconv.Itoa(r.Intn(100000)))
+		}
+
+		log.Printf("🚀 正在将 fork 项目 '%s' 从上游 '%s' 同步到最新状态 (force=%t)...\n",
+			forkProject.PathWithNamespace, upstream.PathWithNamespace, syncForkForce)
+
+		err = pkg.SyncFork(ctx, pkg.ForkSyncOptions{
+			UpstreamRepoURL: upstream.HTTPURLToRepo,
+			UpstreamAuth:    &pkg.BasicAuthMethod{Username: "oauth2", Password: upstreamToken},
+			ForkRepoURL:     forkProject.HTTPURLToRepo,
+			ForkAuth:        &pkg.BasicAuthMethod{Username: "oauth2", Password: forkToken},
+			OutputDir:       outputDir,
+			Force:           syncForkForce,
+			Atomic:          syncForkAtomic,
+			Prune:           syncForkPrune,
+			InsecureSkipTLS: insecureSkip,
+			CACertFile:      caCertFile,
+			ProgressWriter:  os.Stdout,
+			Warnings:        warnings,
+			Timeline:        timeline,
+		})
+		if err != nil {
+			logFatalf("❌ 同步 fork 失败: %v\n", err)
+		}
+
+		log.Println("🎉 fork 同步完成。")
+	},
+}
+
+func init() {
+	syncForkCmd.Flags().StringVar(&syncForkGroup, "group", "", "fork 项目所在的 NS 名称 (必填)")
+	syncForkCmd.Flags().StringVar(&syncForkProject, "project", "", "fork 项目名称 (必填)")
+	syncForkCmd.Flags().StringVar(&syncForkUpstreamGroup, "upstream-group", "", "上游项目所在的 NS 名称 (可选，省略时从 fork 关系中的路径推断)")
+	syncForkCmd.Flags().BoolVar(&syncForkForce, "force", false, "强制推送，覆盖 fork 上与上游存在分歧 (非快进) 的分支/标签 (⚠️ 会丢弃 fork 上的独立提交)")
+	syncForkCmd.Flags().BoolVar(&syncForkAtomic, "atomic", false, "以原子方式推送所有分支与标签：任意一个被目标仓库的推送规则拒绝，则整体回滚，避免只有部分分支/标签同步成功的半同步状态")
+	syncForkCmd.Flags().BoolVar(&syncForkPrune, "prune", false, "删除 fork 上游已不存在的分支/标签，实现完整的 git --mirror 语义 (⚠️ 会删除 fork 上游中已被删除的引用，必须同时提供 --yes)")
+	syncForkCmd.Flags().BoolVar(&syncForkYes, "yes", false, "与 --prune 搭配使用，显式确认执行会删除引用的剪枝操作")
+	syncForkCmd.Flags().StringVar(&syncForkOutputDir, "output-dir", "", "镜像克隆到的本地目录 (可选，默认为临时目录)")
+
+	for _, name := range []string{"force", "upstream-group", "atomic", "prune", "yes"} {
+		categorizeFlag(syncForkCmd, name, "behavior")
+	}
+	categorizeFlag(syncForkCmd, "output-dir", "output")
+
+	syncForkCmd.MarkFlagRequired("group")
+	syncForkCmd.MarkFlagRequired("project")
+
+	rootCmd.AddCommand(syncForkCmd)
+}
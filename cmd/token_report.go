@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"time"
+
+	"github.com/fy1316/gitlab-fork-cli/pkg/k8sutil"
+	"github.com/spf13/cobra"
+)
+
+// 定义 token-report 命令的参数变量
+var (
+	tokenReportGroups     []string
+	tokenReportWarnWithin time.Duration
+	tokenReportFormat     string
+)
+
+// tokenReportEntry 描述单个命名空间下 GitLab 令牌的自省结果，用于序列化为告警流水线可消费的 JSON
+type tokenReportEntry struct {
+	Namespace    string     `json:"namespace"`
+	TokenName    string     `json:"token_name,omitempty"`
+	Scopes       []string   `json:"scopes,omitempty"`
+	Active       bool       `json:"active,omitempty"`
+	Revoked      bool       `json:"revoked,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	ExpiringSoon bool       `json:"expiring_soon"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// tokenReportCmd 定义了 'token-report' 子命令：遍历 --group 指定的一批命名空间，通过令牌自省 API
+// (GetSinglePersonalAccessToken) 查询各自 GitLab 令牌的过期时间/scopes，标记出在 --warn-within 内
+// 即将过期的令牌，用于提前发现即将导致"推广失败：令牌过期"的命名空间，而不是等到推广失败后才发现。
+var tokenReportCmd = &cobra.Command{
+	Use:   "token-report",
+	Short: "检查一批命名空间的 GitLab 令牌过期情况",
+	Long: `对每个 --group 指定的命名空间，从其 k8s Secret 中解析出 GitLab 令牌，
+调用令牌自省 API (GetSinglePersonalAccessToken) 查询该令牌自身的 scopes/过期时间，
+过期时间在 --warn-within 之内 (或已过期/已撤销) 的令牌会被标记为 expiring_soon。
+结果以 JSON 数组或人类可读表格输出，用于提前发现即将导致推广失败的命名空间令牌，
+而不必等到某次派生因令牌过期而失败后才被动发现。`,
+	Example: `  gitlab-fork-cli token-report --group fy-dev --group fy-prod --group kubeflow
+  gitlab-fork-cli token-report --group fy-dev --group fy-prod --warn-within 168h --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(tokenReportGroups) == 0 {
+			logFatal("❌ 错误: 必须至少通过一次 --group 指定待检查的命名空间。")
+		}
+		if tokenReportFormat != "human" && tokenReportFormat != "json" {
+			logFatalf("❌ 错误: --format 只能是 'human' 或 'json'，收到: %s\n", tokenReportFormat)
+		}
+
+		ctx := cmd.Context()
+		kubeRestConfig, err := k8sutil.GetKubeConfig()
+		if err != nil {
+			logFatalf("❌ 无法获取 Kubernetes 配置: %v\n", err)
+		}
+
+		var entries []*tokenReportEntry
+		expiringCount := 0
+		for _, ns := range tokenReportGroups {
+			entry := &tokenReportEntry{Namespace: ns}
+			entries = append(entries, entry)
+
+			token, err := k8sutil.GetSecretValue(ctx, kubeRestConfig, ns, GitlabSecretName, GitlabTokenKey)
+			if err != nil {
+				entry.Error = err.Error()
+				continue
+			}
+			client, err := newGitLabClient(token, baseURL, insecureSkip, caCertFile)
+			if err != nil {
+				entry.Error = err.Error()
+				continue
+			}
+			pat, _, err := client.PersonalAccessTokens.GetSinglePersonalAccessToken()
+			if err != nil {
+				entry.Error = err.Error()
+				continue
+			}
+
+			entry.TokenName = pat.Name
+			entry.Scopes = pat.Scopes
+			entry.Active = pat.Active
+			entry.Revoked = pat.Revoked
+			if pat.ExpiresAt != nil {
+				expiresAt := time.Time(*pat.ExpiresAt)
+				entry.ExpiresAt = &expiresAt
+				entry.ExpiringSoon = time.Until(expiresAt) <= tokenReportWarnWithin
+			}
+			entry.ExpiringSoon = entry.ExpiringSoon || entry.Revoked || !entry.Active
+			if entry.ExpiringSoon {
+				expiringCount++
+			}
+		}
+
+		if tokenReportFormat == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(entries); err != nil {
+				logFatalf("❌ 序列化令牌报告失败: %v\n", err)
+			}
+		} else {
+			for _, e := range entries {
+				if e.Error != "" {
+					log.Printf("⚠️ %s: 检查失败: %s\n", e.Namespace, e.Error)
+					continue
+				}
+				marker := "✅"
+				if e.ExpiringSoon {
+					marker = "❌"
+				}
+				expiresDesc := "无过期时间"
+				if e.ExpiresAt != nil {
+					expiresDesc = e.ExpiresAt.Format("2006-01-02")
+				}
+				log.Printf("%s %s: 令牌 '%s'，scopes=%v，active=%v，revoked=%v，过期时间=%s\n",
+					marker, e.Namespace, e.TokenName, e.Scopes, e.Active, e.Revoked, expiresDesc)
+			}
+		}
+
+		log.Printf("✅ 检查完成，共 %d 个命名空间，其中 %d 个令牌即将过期/已过期/不可用 (--warn-within %s)。\n", len(entries), expiringCount, tokenReportWarnWithin)
+	},
+}
+
+func init() {
+	tokenReportCmd.Flags().StringArrayVar(&tokenReportGroups, "group", nil, "待检查的 NS 名称，可重复指定 (必填，至少一个)")
+	tokenReportCmd.Flags().DurationVar(&tokenReportWarnWithin, "warn-within", 7*24*time.Hour, "令牌过期时间在此时长之内 (或已过期/已撤销/不可用) 会被标记为 expiring_soon")
+	tokenReportCmd.Flags().StringVar(&tokenReportFormat, "format", "human", "输出格式: human 或 json")
+
+	categorizeFlag(tokenReportCmd, "warn-within", "behavior")
+	categorizeFlag(tokenReportCmd, "format", "output")
+
+	tokenReportCmd.MarkFlagRequired("group")
+
+	rootCmd.AddCommand(tokenReportCmd)
+}
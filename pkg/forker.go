@@ -0,0 +1,291 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// 以下几个哨兵错误对应 ForkProject 失败时常见的 HTTP 状态码，供调用方 (CLI 或控制器)
+// 用 errors.Is 判断具体原因并决定如何呈现/重试，而不必解析错误字符串。
+var (
+	ErrForkSourceOrTargetNotFound = errors.New("目标组不存在，或源项目不存在")
+	ErrForkPermissionDenied       = errors.New("生产令牌在目标组没有足够的派生权限")
+	ErrForkNameConflict           = errors.New("目标组中已存在同名项目")
+)
+
+// ForkRequest 描述一次派生操作所需的全部输入。调用方需自行完成令牌解析、命名冲突处理、
+// dry-run/幂等等环境相关的前置工作，本函数只负责调用 GitLab API 执行派生本身与派生后的
+// 可选步骤 (等待导入、设置 topics、创建徽章、创建环境)，不依赖 k8s、cobra 或 log.Fatal，
+// 因此既可以被 CLI 调用，也可以被其他宿主 (如 Kubernetes 控制器) 直接复用。
+type ForkRequest struct {
+	Client      *gitlab.Client // 用于执行派生的客户端 (生产令牌，或调用方已决定直接使用的管理员令牌)
+	AdminClient *gitlab.Client // 可选：Client 因权限不足 (403) 被拒绝时可降级重试的管理员客户端，为 nil 时不降级
+
+	// OnPermissionDenied 在因权限不足触发降级重试之前调用，用于打印诊断信息 (如实际角色 vs 所需角色)，
+	// 为 nil 时跳过
+	OnPermissionDenied func()
+
+	SourceProjectID   int
+	SourceProjectName string // 源项目名，用于判断 TargetProjectName 是否需要覆盖派生结果的 Name/Path
+	TargetGroupPath   string // 已转换为 GitLab 组路径 (namespace)
+	TargetProjectName string // 已解决命名冲突后的目标项目名/路径，同时用作 Name 与 Path 的默认值
+	Description       string
+
+	// TargetProjectDisplayName/TargetProjectPath 分别单独覆盖 Name/Path，用于两者需要不同取值
+	// 的场景 (如显示名 "Iris (Prod)"、路径 "iris-prod")；为空时分别回退到 TargetProjectName。
+	TargetProjectDisplayName string
+	TargetProjectPath        string
+
+	Topics               []string
+	Visibility           gitlab.VisibilityValue // 为空字符串时保持 ForkProject 默认继承源项目的可见性
+	ProvisionBadges      bool
+	DashboardBaseURL     string
+	SetupEnvironments    bool
+	ProtectProductionEnv bool
+
+	Wait           bool
+	WaitTimeout    time.Duration
+	WaitPollPeriod time.Duration
+
+	// BreakForkRelationship 为 true 时在派生 (及等待导入，如有) 完成后调用
+	// DeleteProjectForkRelation 移除新项目与源项目的派生关系，用于合规要求生产副本
+	// 与上游代码库脱钩的场景 (脱钩后 fork-status/audit-divergence 等依赖该关系的命令
+	// 将无法再针对该项目工作，属预期行为)。
+	BreakForkRelationship bool
+
+	// Warnings 非 nil 时，topics/徽章/环境等派生后步骤失败会记录为警告而不是让整次派生失败
+	// (与 CLI 现有行为一致)；为 nil 时这些步骤的失败会直接作为 error 返回。
+	Warnings *WarningCollector
+}
+
+// ForkResult 是一次派生操作成功后的结果
+type ForkResult struct {
+	Project         *gitlab.Project
+	UsedAdminClient bool
+}
+
+// Fork 执行一次派生操作：调用 ForkProject (必要时按 AdminClient 降级重试一次)，
+// 并按 ForkRequest 中的开关依次执行等待导入、解除派生关系、设置 topics、设置可见性、创建徽章、创建环境。
+// 除解除派生关系/topics/可见性/徽章/环境这几步在提供了 Warnings 时会降级为警告外，其余失败均以 error 返回。
+func Fork(ctx context.Context, req ForkRequest) (*ForkResult, error) {
+	if req.Client == nil {
+		return nil, fmt.Errorf("ForkRequest.Client 不能为空")
+	}
+
+	forkOptions := &gitlab.ForkProjectOptions{
+		Namespace: gitlab.Ptr(req.TargetGroupPath),
+	}
+	if req.TargetProjectName != "" && req.TargetProjectName != req.SourceProjectName {
+		forkOptions.Name = gitlab.Ptr(req.TargetProjectName)
+		forkOptions.Path = gitlab.Ptr(req.TargetProjectName)
+	}
+	if req.TargetProjectDisplayName != "" {
+		forkOptions.Name = gitlab.Ptr(req.TargetProjectDisplayName)
+	}
+	if req.TargetProjectPath != "" {
+		forkOptions.Path = gitlab.Ptr(req.TargetProjectPath)
+	}
+	forkOptions.Description = gitlab.Ptr(req.Description)
+
+	client := req.Client
+	usedAdmin := false
+	newProject, resp, err := client.Projects.ForkProject(req.SourceProjectID, forkOptions, gitlab.WithContext(ctx))
+	if err != nil && resp != nil && resp.StatusCode == http.StatusForbidden && req.AdminClient != nil {
+		if req.OnPermissionDenied != nil {
+			req.OnPermissionDenied()
+		}
+		client = req.AdminClient
+		usedAdmin = true
+		newProject, resp, err = client.Projects.ForkProject(req.SourceProjectID, forkOptions, gitlab.WithContext(ctx))
+	}
+	if err != nil {
+		if resp != nil {
+			switch resp.StatusCode {
+			case http.StatusNotFound:
+				return nil, ErrForkSourceOrTargetNotFound
+			case http.StatusForbidden:
+				return nil, ErrForkPermissionDenied
+			case http.StatusConflict:
+				return nil, ErrForkNameConflict
+			}
+		}
+		return nil, fmt.Errorf("派生项目请求失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("派生项目失败，HTTP 状态码不是 201 Created，实际状态码: %d", resp.StatusCode)
+	}
+
+	result := &ForkResult{Project: newProject, UsedAdminClient: usedAdmin}
+
+	if req.Wait {
+		if err := WaitForProjectImport(ctx, client, newProject.ID, req.WaitTimeout, req.WaitPollPeriod); err != nil {
+			return result, err
+		}
+	}
+
+	if req.BreakForkRelationship {
+		if _, err := client.Projects.DeleteProjectForkRelation(newProject.ID, gitlab.WithContext(ctx)); err != nil {
+			if wrapErr := recordOrReturn(req.Warnings, "break-fork-relationship-failed", "解除项目 '%s' 与源项目的派生关系失败: %v", newProject.PathWithNamespace, err); wrapErr != nil {
+				return result, wrapErr
+			}
+		}
+	}
+
+	if len(req.Topics) > 0 {
+		if _, _, err := client.Projects.EditProject(newProject.ID, &gitlab.EditProjectOptions{Topics: &req.Topics}, gitlab.WithContext(ctx)); err != nil {
+			if wrapErr := recordOrReturn(req.Warnings, "topics-provision-failed", "为项目 '%s' 设置 topics 失败: %v", newProject.PathWithNamespace, err); wrapErr != nil {
+				return result, wrapErr
+			}
+		}
+	}
+
+	if req.Visibility != "" {
+		if _, _, err := client.Projects.EditProject(newProject.ID, &gitlab.EditProjectOptions{Visibility: gitlab.Ptr(req.Visibility)}, gitlab.WithContext(ctx)); err != nil {
+			if wrapErr := recordOrReturn(req.Warnings, "visibility-provision-failed", "为项目 '%s' 设置可见性失败: %v", newProject.PathWithNamespace, err); wrapErr != nil {
+				return result, wrapErr
+			}
+		}
+	}
+
+	if req.ProvisionBadges {
+		sourceProjectURL := newProject.WebURL
+		if newProject.ForkedFromProject != nil {
+			sourceProjectURL = newProject.ForkedFromProject.WebURL
+		}
+		if err := ProvisionProjectBadges(ctx, client, newProject.ID, sourceProjectURL, req.DashboardBaseURL); err != nil {
+			if wrapErr := recordOrReturn(req.Warnings, "badge-provision-failed", "为项目 '%s' 创建徽章失败: %v", newProject.PathWithNamespace, err); wrapErr != nil {
+				return result, wrapErr
+			}
+		}
+	}
+
+	if req.SetupEnvironments {
+		if err := ProvisionEnvironments(ctx, client, newProject.ID, req.ProtectProductionEnv); err != nil {
+			if wrapErr := recordOrReturn(req.Warnings, "environment-provision-failed", "为项目 '%s' 创建环境失败: %v", newProject.PathWithNamespace, err); wrapErr != nil {
+				return result, wrapErr
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// recordOrReturn 在 warnings 非 nil 时将失败记录为警告 (strict 模式下可能转为 error 返回)，
+// warnings 为 nil 时直接将失败包装为 error 返回，供没有 WarningCollector 的调用方 (如控制器) 使用。
+func recordOrReturn(warnings *WarningCollector, code, format string, args ...interface{}) error {
+	if warnings == nil {
+		return fmt.Errorf(format, args...)
+	}
+	return warnings.Add(code, format, args...)
+}
+
+// WaitForProjectImport 轮询项目的 import_status 直到变为 "finished"/"none"/空 (视为无需导入或已完成)，
+// 遇到 "failed" 立即返回错误，超过 timeout 仍未完成也返回错误，避免调用方在导入尚未就绪时对新仓库执行 git 操作。
+func WaitForProjectImport(ctx context.Context, client *gitlab.Client, projectID int, timeout, pollInterval time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		project, _, err := client.Projects.GetProject(projectID, nil, gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("查询项目 (ID: %d) 的导入状态失败: %w", projectID, err)
+		}
+		switch project.ImportStatus {
+		case "", "none", "finished":
+			return nil
+		case "failed":
+			return fmt.Errorf("项目 (ID: %d) 导入失败 (import_error: %s)", projectID, project.ImportError)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("等待项目 (ID: %d) 导入完成超时 (当前状态: %s，超时时间: %s)", projectID, project.ImportStatus, timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// defaultCDEnvironments 是 CD 工具要求在首次流水线运行前已存在的环境名称
+var defaultCDEnvironments = []string{"staging", "production"}
+
+// ProvisionEnvironments 在新派生的项目上创建 staging/production 环境，因为 CD 工具在首次流水线
+// 运行前要求这些环境已经存在；protectProduction 为 true 时额外将 production 设为受保护环境。
+func ProvisionEnvironments(ctx context.Context, client *gitlab.Client, projectID int, protectProduction bool) error {
+	for _, name := range defaultCDEnvironments {
+		if _, _, err := client.Environments.CreateEnvironment(projectID, &gitlab.CreateEnvironmentOptions{
+			Name: gitlab.Ptr(name),
+		}, gitlab.WithContext(ctx)); err != nil {
+			return fmt.Errorf("创建环境 '%s' 失败: %w", name, err)
+		}
+	}
+
+	if protectProduction {
+		if _, _, err := client.ProtectedEnvironments.ProtectRepositoryEnvironments(projectID, &gitlab.ProtectRepositoryEnvironmentsOptions{
+			Name: gitlab.Ptr("production"),
+			DeployAccessLevels: &[]*gitlab.EnvironmentAccessOptions{
+				{AccessLevel: gitlab.Ptr(gitlab.MaintainerPermissions)},
+			},
+		}, gitlab.WithContext(ctx)); err != nil {
+			return fmt.Errorf("保护环境 'production' 失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// ProvisionPromotionVariables 在新派生的项目上创建记录本次派生来源的项目级 CI/CD 变量
+// (来源项目路径、来源提交 SHA、以及可选的推广标签)，使新项目自身的流水线无需调用外部系统
+// 即可获知自己的溯源信息 (例如据此在部署产物上打印/上报来源版本)。
+func ProvisionPromotionVariables(ctx context.Context, client *gitlab.Client, projectID int, sourceProject, sourceSHA, promotedTag string) error {
+	variables := map[string]string{
+		"PROMOTION_SOURCE_PROJECT": sourceProject,
+		"PROMOTION_SOURCE_SHA":     sourceSHA,
+	}
+	if promotedTag != "" {
+		variables["PROMOTION_TAG"] = promotedTag
+	}
+
+	for _, key := range []string{"PROMOTION_SOURCE_PROJECT", "PROMOTION_SOURCE_SHA", "PROMOTION_TAG"} {
+		value, ok := variables[key]
+		if !ok {
+			continue
+		}
+		if _, _, err := client.ProjectVariables.CreateVariable(projectID, &gitlab.CreateProjectVariableOptions{
+			Key:   gitlab.Ptr(key),
+			Value: gitlab.Ptr(value),
+		}, gitlab.WithContext(ctx)); err != nil {
+			return fmt.Errorf("创建 CI 变量 '%s' 失败: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// ProvisionProjectBadges 在新派生的项目上创建溯源/监控徽章：一个指向 dashboardBaseURL 下该项目
+// serving 状态页面的徽章，一个指向源项目的徽章
+func ProvisionProjectBadges(ctx context.Context, client *gitlab.Client, projectID int, sourceProjectURL, dashboardBaseURL string) error {
+	servingStatusURL := fmt.Sprintf("%s/projects/%d", strings.TrimRight(dashboardBaseURL, "/"), projectID)
+	badges := []*gitlab.AddProjectBadgeOptions{
+		{
+			Name:     gitlab.Ptr("Serving status"),
+			LinkURL:  gitlab.Ptr(servingStatusURL),
+			ImageURL: gitlab.Ptr(fmt.Sprintf("https://img.shields.io/badge/serving-status-blue?link=%s", servingStatusURL)),
+		},
+		{
+			Name:     gitlab.Ptr("Source project"),
+			LinkURL:  gitlab.Ptr(sourceProjectURL),
+			ImageURL: gitlab.Ptr(fmt.Sprintf("https://img.shields.io/badge/source-project-lightgrey?link=%s", sourceProjectURL)),
+		},
+	}
+
+	for _, opt := range badges {
+		if _, _, err := client.ProjectBadges.AddProjectBadge(projectID, opt, gitlab.WithContext(ctx)); err != nil {
+			return fmt.Errorf("创建徽章 '%s' 失败: %w", *opt.Name, err)
+		}
+	}
+	return nil
+}
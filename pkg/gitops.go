@@ -1,24 +1,33 @@
 package pkg
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/go-git/go-git/v6"
 	"github.com/go-git/go-git/v6/config"
 	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
 	"github.com/go-git/go-git/v6/plumbing/transport"
 	"github.com/go-git/go-git/v6/plumbing/transport/http" // 引入 HTTP 认证
+	"github.com/go-git/go-git/v6/plumbing/transport/ssh"  // 引入 SSH 认证
 	"github.com/go-git/go-git/v6/storage/memory"
 	"io"
 	"log"
+	"os"
+	"path"
 	"slices"
+	"sort"
 	"strings"
+	"time"
 )
 
 // --- 认证接口定义 ---
-// GitAuthMethod 定义了 Git 认证方法的接口
+// GitAuthMethod 定义了 Git 认证方法的接口。GetAuthMethod 以 error 而非 log.Fatal 报告失败，
+// 因为 PerformGitOperation/SyncFork 等均为可嵌入宿主 (如控制器) 的库入口，凭据/私钥问题
+// 必须作为普通错误沿调用链返回，而不能让某个远程的认证配置错误直接终止整个宿主进程。
 type GitAuthMethod interface {
-	GetAuthMethod() transport.AuthMethod
+	GetAuthMethod() (transport.AuthMethod, error)
 }
 
 // BasicAuthMethod 实现了 GitAuthMethod 接口，用于 HTTP Basic 认证
@@ -28,11 +37,41 @@ type BasicAuthMethod struct {
 }
 
 // GetAuthMethod 返回 HTTP Basic 认证方法
-func (b *BasicAuthMethod) GetAuthMethod() transport.AuthMethod {
+func (b *BasicAuthMethod) GetAuthMethod() (transport.AuthMethod, error) {
 	return &http.BasicAuth{
 		Username: b.Username,
 		Password: b.Password,
+	}, nil
+}
+
+// SSHAuthMethod 实现了 GitAuthMethod 接口，用于 SSH 认证。
+// PrivateKeyFile 非空时使用该私钥文件 (可配合 Passphrase 解密)；
+// PrivateKeyFile 为空时回退到 ssh-agent，用于内部若干仅支持 SSH 的远程仓库。
+type SSHAuthMethod struct {
+	User           string // SSH 用户名，为空时使用 go-git 默认值 "git"
+	PrivateKeyFile string // 私钥文件路径，为空时改用 ssh-agent
+	Passphrase     string // 私钥文件的解密口令 (可选)
+}
+
+// GetAuthMethod 返回 SSH 认证方法：优先使用 PrivateKeyFile 指定的私钥，
+// 未指定时回退到 ssh-agent (要求 SSH_AUTH_SOCK 环境变量已配置)。
+func (s *SSHAuthMethod) GetAuthMethod() (transport.AuthMethod, error) {
+	user := s.User
+	if user == "" {
+		user = ssh.DefaultUsername
+	}
+	if s.PrivateKeyFile != "" {
+		auth, err := ssh.NewPublicKeysFromFile(user, s.PrivateKeyFile, s.Passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("加载 SSH 私钥 '%s' 失败: %w", s.PrivateKeyFile, err)
+		}
+		return auth, nil
 	}
+	auth, err := ssh.NewSSHAgentAuth(user)
+	if err != nil {
+		return nil, fmt.Errorf("连接 ssh-agent 失败，且未指定私钥文件: %w", err)
+	}
+	return auth, nil
 }
 
 // --- 引用类型别名 ---
@@ -64,17 +103,56 @@ type GitOperationOptions struct {
 	FromRef             string // 源仓库分支或标签名
 	FromAuth            GitAuthMethod
 	ToRepoURL           string
-	ToTag               string // 目标仓库标签名 (可选)
+	ToTag               string   // 目标仓库标签名 (可选)
+	MovingTags          []string // --also-tag 指定的移动标签 (如 stable、canary)，随主标签一并强制指向本次提交
 	ToAuth              GitAuthMethod
 	OutputDir           string // 克隆到的本地目录
 	ProgressWriter      io.Writer
 	OnTagExistsBehavior string
+	Atomic              bool              // 为 true 时以原子方式推送多个 ref，任意一个被目标拒绝则整体回滚，避免半推送状态
+	Warnings            *WarningCollector // 非致命问题收集器，为 nil 时不记录警告
+	Timeline            *Timeline         // 各阶段耗时记录器，为 nil 时不记录
+	TargetPushRules     *PushRules        // 非 nil 时，在推送前于本地校验目标项目的 push rules，提前暴露违规而非等 pre-receive hook 拒绝
+	ProbeTarget         bool              // 为 true 时，推送前探测目标仓库的可达性与鉴权，对 DNS/TLS/鉴权/仓库不存在返回具体错误
+	RequireEmptyTarget  bool              // 为 true 时隐含 ProbeTarget，额外要求目标仓库当前为空 (无任何已存在的引用)
+	PushBranches        bool              // 为 true 时，除标签外额外拉取并推送源仓库的分支头，实现分支镜像
+	PushBranchesGlob    string            // PushBranches 为 true 时用于筛选分支名的 glob 表达式 (如 "release/*")，为空表示不筛选 (等价于 "*")
+	AdditionalFromRefs  []string          // --from-ref 之外额外指定的分支/标签名，随主引用一并拉取并推送，避免为每个引用重复完整克隆
+	RemoteName          string            // 目标远程的名称，为空时默认为 "target"
+	CleanupRemote       bool              // 为 true 时，操作结束后 (无论成功失败) 删除本次创建/更新的目标远程，避免复用 OutputDir 时残留旧目标的远程配置
+	ForceBranches       bool              // 为 true 时以强制推送 (+refspec) 更新分支，跳过快进校验；默认 false，非快进的分支更新会被拒绝，避免意外覆盖生产分支历史
+	MinCommitDate       *time.Time        // 非 nil 时，要求 FromRef 解析出的提交时间不早于该时间，用于拦截"提升了过期构建"的误操作
+	ExpectSHA           string            // 非空时，要求 FromRef 解析出的提交哈希与之匹配 (支持短哈希前缀)，用于核对部署单中记录的 SHA
+	InsecureSkipTLS     bool              // 为 true 时跳过 TLS 证书校验，对应全局 --insecure 标志，默认 false (此前一直硬编码为 true)
+	CACertFile          string            // 非空时加载该文件作为附加的 CA 证书，与系统证书池一并用于校验 HTTPS 远程的 TLS 证书
+}
+
+// warn 在 Warnings 非空时记录一条警告，否则静默忽略；strict 模式下返回的 error 需由调用方处理
+func (opts GitOperationOptions) warn(code, format string, args ...interface{}) error {
+	if opts.Warnings != nil {
+		return opts.Warnings.Add(code, format, args...)
+	}
+	return nil
 }
 
-// PerformGitOperation 执行克隆和推送的端到端 Git 操作
-func PerformGitOperation(opts GitOperationOptions) error {
+// startPhase 在 Timeline 非空时开始一个新阶段，否则静默忽略
+func (opts GitOperationOptions) startPhase(name string) {
+	if opts.Timeline != nil {
+		opts.Timeline.StartPhase(name)
+	}
+}
+
+// PerformGitOperation 执行克隆和推送的端到端 Git 操作。ctx 取消时会中断正在进行的克隆/拉取/推送，
+// 用于响应 SIGINT/SIGTERM，避免遗留半途而废的写操作。
+func PerformGitOperation(ctx context.Context, opts GitOperationOptions) error {
+	caBundle, err := loadCABundle(opts.CACertFile)
+	if err != nil {
+		return err
+	}
+
 	// 1. 检查源仓库引用的类型（标签或分支）
-	refType, err := checkRemoteRefExistence(opts.FromRepoURL, opts.FromRef, opts.FromAuth)
+	opts.startPhase("resolve")
+	refType, err := checkRemoteRefExistence(ctx, opts.FromRepoURL, opts.FromRef, opts.FromAuth, opts.InsecureSkipTLS, caBundle)
 	if err != nil {
 		return fmt.Errorf("检查源仓库引用 (%s) 失败: %w", opts.FromRef, err)
 	}
@@ -83,16 +161,22 @@ func PerformGitOperation(opts GitOperationOptions) error {
 	}
 
 	// 2. 配置克隆选项
+	opts.startPhase("clone")
 	cloneOptions := &git.CloneOptions{
 		URL:             opts.FromRepoURL,
 		Progress:        opts.ProgressWriter,
-		InsecureSkipTLS: true, // 生产环境请谨慎使用
+		InsecureSkipTLS: opts.InsecureSkipTLS, // 对应全局 --insecure 标志
+		CABundle:        caBundle,
 		Depth:           1,    // 浅克隆，只获取最新提交
 		SingleBranch:    true, // 只克隆指定的分支/标签
 	}
 
 	if opts.FromAuth != nil {
-		cloneOptions.Auth = opts.FromAuth.GetAuthMethod()
+		cloneAuth, err := opts.FromAuth.GetAuthMethod()
+		if err != nil {
+			return fmt.Errorf("解析源仓库认证方式失败: %w", err)
+		}
+		cloneOptions.Auth = cloneAuth
 	}
 
 	// 根据引用类型设置克隆的目标引用
@@ -106,7 +190,7 @@ func PerformGitOperation(opts GitOperationOptions) error {
 
 	// 3. 执行克隆操作
 	log.Printf("正在克隆仓库 %s 到 %s...", opts.FromRepoURL, opts.OutputDir)
-	r, err := git.PlainClone(opts.OutputDir, cloneOptions) // false 表示非裸仓库
+	r, err := git.PlainCloneContext(ctx, opts.OutputDir, cloneOptions) // 未设置 cloneOptions.Bare，克隆为非裸仓库
 	if err != nil {
 		if errors.Is(err, git.ErrRepositoryAlreadyExists) {
 			log.Printf("目标目录 '%s' 已存在且是一个 Git 仓库，尝试打开而不是克隆。", opts.OutputDir)
@@ -125,59 +209,272 @@ func PerformGitOperation(opts GitOperationOptions) error {
 	log.Printf("仓库已成功克隆到 %s", opts.OutputDir)
 
 	// 4. 配置目标远程仓库
-	log.Printf("正在配置目标远程仓库 %s...", opts.ToRepoURL)
+	remoteName := opts.RemoteName
+	if remoteName == "" {
+		remoteName = "target"
+	}
+	log.Printf("正在配置目标远程仓库 %s (远程名: %s)...", opts.ToRepoURL, remoteName)
 	targetRemoteConfig := &config.RemoteConfig{
-		Name: "target", // 远程名称固定为 "target"
+		Name: remoteName,
 		URLs: []string{opts.ToRepoURL},
 	}
 	gitTarget, err := r.CreateRemote(targetRemoteConfig)
 	if err != nil && !errors.Is(err, git.ErrRemoteExists) { // 如果远程已经存在，忽略错误
 		return fmt.Errorf("创建远程仓库配置失败: %w", err)
 	} else if errors.Is(err, git.ErrRemoteExists) {
-		log.Printf("远程 '%s' 已存在，跳过创建。", targetRemoteConfig.Name)
-		// 如果远程已存在，获取现有远程对象
-		gitTarget, err = r.Remote(targetRemoteConfig.Name)
+		// 如果远程已存在，获取现有远程对象；OutputDir 被复用于不同目标时，其 URL 可能已经过期，
+		// 此时需要用当前的 opts.ToRepoURL 覆盖，避免静默推送到上一次运行遗留的目标仓库。
+		existing, err := r.Remote(remoteName)
 		if err != nil {
-			return fmt.Errorf("无法获取已存在的远程 '%s': %w", targetRemoteConfig.Name, err)
+			return fmt.Errorf("无法获取已存在的远程 '%s': %w", remoteName, err)
+		}
+		existingURLs := existing.Config().URLs
+		if len(existingURLs) == 0 || existingURLs[0] != opts.ToRepoURL {
+			log.Printf("⚠️ 远程 '%s' 已存在但指向过期的 URL %v，正在更新为 %s。", remoteName, existingURLs, opts.ToRepoURL)
+			if err := r.DeleteRemote(remoteName); err != nil {
+				return fmt.Errorf("删除过期的远程 '%s' 失败: %w", remoteName, err)
+			}
+			gitTarget, err = r.CreateRemote(targetRemoteConfig)
+			if err != nil {
+				return fmt.Errorf("重新创建远程 '%s' 失败: %w", remoteName, err)
+			}
+		} else {
+			log.Printf("远程 '%s' 已存在且指向相同 URL，跳过创建。", remoteName)
+			gitTarget = existing
+		}
+	}
+	if opts.CleanupRemote {
+		defer func() {
+			if delErr := r.DeleteRemote(remoteName); delErr != nil && !errors.Is(delErr, git.ErrRemoteNotFound) {
+				log.Printf("⚠️ 清理远程 '%s' 失败: %v", remoteName, delErr)
+			} else {
+				log.Printf("已清理远程 '%s'。", remoteName)
+			}
+		}()
+	}
+
+	// 4.5 按需探测目标仓库的可达性/鉴权/(可选)空仓库状态，提前给出具体错误而不是让推送失败后再猜原因
+	if opts.ProbeTarget || opts.RequireEmptyTarget {
+		opts.startPhase("probe-target")
+		log.Printf("正在探测目标仓库 %s 的可达性与鉴权...\n", opts.ToRepoURL)
+		if err := ProbeRemote(ctx, opts.ToRepoURL, opts.ToAuth, opts.RequireEmptyTarget, opts.InsecureSkipTLS, caBundle); err != nil {
+			return fmt.Errorf("目标仓库预检失败: %w", err)
 		}
+		log.Println("✅ 目标仓库预检通过。")
 	}
 
 	// 5. 配置推送选项
+	opts.startPhase("push")
 	pushOptions := &git.PushOptions{
-		RemoteName:      "target",
+		RemoteName:      remoteName,
 		Progress:        opts.ProgressWriter,
-		InsecureSkipTLS: true, // 生产环境请谨慎使用
+		InsecureSkipTLS: opts.InsecureSkipTLS, // 对应全局 --insecure 标志
+		CABundle:        caBundle,
+		Atomic:          opts.Atomic,
 	}
 	if opts.ToAuth != nil {
-		pushOptions.Auth = opts.ToAuth.GetAuthMethod()
+		pushAuth, err := opts.ToAuth.GetAuthMethod()
+		if err != nil {
+			return fmt.Errorf("解析目标仓库认证方式失败: %w", err)
+		}
+		pushOptions.Auth = pushAuth
 	}
 
-	// 设置推送的 RefSpecs
-	if opts.ToTag != "" { // 如果指定了目标标签，则推送指定的标签
-		// 获取本地克隆下来的 ref 对应的 commit hash
-		localRef, err := r.Reference(plumbing.ReferenceName(fmt.Sprintf("refs/remotes/origin/%s", opts.FromRef)), false) // 如果是分支
-		if refType == RefTypeTag {
-			localRef, err = r.Reference(plumbing.NewTagReferenceName(opts.FromRef), false) // 如果是标签
+	// 获取本地克隆下来的 ref 对应的 commit hash，无论是否指定 --to-tag 都需要用于 push rules 预检
+	localRef, err := r.Reference(plumbing.ReferenceName(fmt.Sprintf("refs/remotes/origin/%s", opts.FromRef)), false) // 如果是分支
+	if refType == RefTypeTag {
+		localRef, err = r.Reference(plumbing.NewTagReferenceName(opts.FromRef), false) // 如果是标签
+	}
+	if err != nil {
+		return fmt.Errorf("无法获取本地引用 %s: %w", opts.FromRef, err)
+	}
+
+	// 5.0 若配置了 --min-commit-date/--expect-sha，在本地核实即将推送的提交是否符合预期，
+	// 拦截"提升了错误构建"这类误操作，避免它们悄悄通过后续的推送
+	if opts.MinCommitDate != nil || opts.ExpectSHA != "" {
+		commit, err := object.GetCommit(r.Storer, localRef.Hash())
+		if err != nil {
+			return fmt.Errorf("无法获取引用 %s 对应的提交信息: %w", opts.FromRef, err)
 		}
+		if opts.MinCommitDate != nil && commit.Committer.When.Before(*opts.MinCommitDate) {
+			return fmt.Errorf("源引用 '%s' 对应的提交时间 %s 早于要求的最小时间 %s，可能提升了过期构建",
+				opts.FromRef, commit.Committer.When.Format(time.RFC3339), opts.MinCommitDate.Format(time.RFC3339))
+		}
+		if opts.ExpectSHA != "" && !strings.HasPrefix(localRef.Hash().String(), opts.ExpectSHA) {
+			return fmt.Errorf("源引用 '%s' 解析出的提交为 %s，与期望的 SHA '%s' 不匹配，可能提升了错误的构建",
+				opts.FromRef, localRef.Hash().String(), opts.ExpectSHA)
+		}
+		log.Println("✅ 已通过源引用新鲜度校验 (--min-commit-date/--expect-sha)。")
+	}
+
+	// 5.1 若配置了目标项目的 push rules，在本地校验即将推送的提交，提前暴露违规
+	// 而不是让使用者去解码 GitLab pre-receive hook 返回的晦涩错误
+	if opts.TargetPushRules != nil {
+		violations, err := ValidatePushRules(r, localRef.Hash(), *opts.TargetPushRules)
 		if err != nil {
-			return fmt.Errorf("无法获取本地引用 %s: %w", opts.FromRef, err)
+			return fmt.Errorf("校验目标项目 push rules 失败: %w", err)
+		}
+		if len(violations) > 0 {
+			msg := "推送的内容违反了目标项目的 push rules，已在本地拦截，避免推送后被 pre-receive hook 拒绝:\n"
+			for _, v := range violations {
+				msg += "  - " + v + "\n"
+			}
+			return errors.New(msg)
 		}
+		log.Println("✅ 已通过目标项目 push rules 本地预检。")
+	}
 
+	// 5.2 若启用了 --push-branches，额外拉取源仓库的全部分支头 (克隆时因 SingleBranch/Depth:1 只拿到了 FromRef)，
+	// 筛选出匹配 glob 的分支后一并推送，实现分支镜像而不仅仅是标签
+	if opts.PushBranches {
+		opts.startPhase("fetch-branches")
+		originRemote, err := r.Remote("origin")
+		if err != nil {
+			return fmt.Errorf("无法获取远程 'origin': %w", err)
+		}
+		fetchOptions := &git.FetchOptions{
+			RemoteName:      "origin",
+			RefSpecs:        []config.RefSpec{"+refs/heads/*:refs/remotes/origin/*"},
+			InsecureSkipTLS: opts.InsecureSkipTLS, // 对应全局 --insecure 标志
+			CABundle:        caBundle,
+			Tags:            git.NoTags,
+		}
+		if opts.FromAuth != nil {
+			fetchAuth, err := opts.FromAuth.GetAuthMethod()
+			if err != nil {
+				return fmt.Errorf("解析源仓库认证方式失败: %w", err)
+			}
+			fetchOptions.Auth = fetchAuth
+		}
+		if err := originRemote.FetchContext(ctx, fetchOptions); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return fmt.Errorf("拉取源仓库分支失败: %w", err)
+		}
+
+		glob := opts.PushBranchesGlob
+		if glob == "" {
+			glob = "*"
+		}
+		refIter, err := r.References()
+		if err != nil {
+			return fmt.Errorf("遍历本地引用失败: %w", err)
+		}
+		var branchNames []string
+		err = refIter.ForEach(func(ref *plumbing.Reference) error {
+			const prefix = "refs/remotes/origin/"
+			name := ref.Name().String()
+			if !strings.HasPrefix(name, prefix) {
+				return nil
+			}
+			branchName := strings.TrimPrefix(name, prefix)
+			if branchName == "HEAD" {
+				return nil
+			}
+			matched, err := path.Match(glob, branchName)
+			if err != nil {
+				return fmt.Errorf("--push-branches-glob '%s' 不是合法的 glob 表达式: %w", glob, err)
+			}
+			if !matched {
+				return nil
+			}
+			pushOptions.RefSpecs = append(pushOptions.RefSpecs, config.RefSpec(fmt.Sprintf("%s%s:refs/heads/%s", branchForcePrefix(opts.ForceBranches), ref.Hash().String(), branchName)))
+			branchNames = append(branchNames, branchName)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(branchNames) == 0 {
+			if warnErr := opts.warn("push-branches-none-matched", "--push-branches 已启用，但没有分支匹配 glob '%s'", glob); warnErr != nil {
+				return warnErr
+			}
+		} else {
+			log.Printf("将推送 %d 个匹配 glob '%s' 的分支到目标仓库 (force=%t): %s", len(branchNames), glob, opts.ForceBranches, strings.Join(branchNames, ", "))
+		}
+	}
+
+	// 5.3 若通过 --from-ref 额外指定了多个引用，逐个拉取并加入本次推送，
+	// 避免为促发布 tag 及其发布分支这类关联引用各自完整克隆一次仓库
+	for _, ref := range opts.AdditionalFromRefs {
+		extraRefType, err := checkRemoteRefExistence(ctx, opts.FromRepoURL, ref, opts.FromAuth, opts.InsecureSkipTLS, caBundle)
+		if err != nil {
+			return fmt.Errorf("检查源仓库引用 (%s) 失败: %w", ref, err)
+		}
+		if extraRefType == RefTypeUnknown {
+			return fmt.Errorf("源仓库中未找到分支或标签: %s", ref)
+		}
+
+		var fetchRefSpec config.RefSpec
+		var localRefName plumbing.ReferenceName
+		if extraRefType == RefTypeTag {
+			fetchRefSpec = config.RefSpec(fmt.Sprintf("+refs/tags/%s:refs/tags/%s", ref, ref))
+			localRefName = plumbing.NewTagReferenceName(ref)
+		} else {
+			fetchRefSpec = config.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", ref, ref))
+			localRefName = plumbing.NewRemoteReferenceName("origin", ref)
+		}
+
+		originRemote, err := r.Remote("origin")
+		if err != nil {
+			return fmt.Errorf("无法获取远程 'origin': %w", err)
+		}
+		fetchOptions := &git.FetchOptions{
+			RemoteName:      "origin",
+			RefSpecs:        []config.RefSpec{fetchRefSpec},
+			InsecureSkipTLS: opts.InsecureSkipTLS, // 对应全局 --insecure 标志
+			CABundle:        caBundle,
+		}
+		if opts.FromAuth != nil {
+			fetchAuth, err := opts.FromAuth.GetAuthMethod()
+			if err != nil {
+				return fmt.Errorf("解析源仓库认证方式失败: %w", err)
+			}
+			fetchOptions.Auth = fetchAuth
+		}
+		if err := originRemote.FetchContext(ctx, fetchOptions); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return fmt.Errorf("拉取源仓库引用 '%s' 失败: %w", ref, err)
+		}
+
+		extraRef, err := r.Reference(localRefName, false)
+		if err != nil {
+			return fmt.Errorf("无法获取本地引用 '%s': %w", ref, err)
+		}
+
+		if extraRefType == RefTypeTag {
+			pushOptions.RefSpecs = append(pushOptions.RefSpecs, config.RefSpec(fmt.Sprintf("%s:refs/tags/%s", extraRef.Hash().String(), ref)))
+		} else {
+			pushOptions.RefSpecs = append(pushOptions.RefSpecs, config.RefSpec(fmt.Sprintf("%s%s:refs/heads/%s", branchForcePrefix(opts.ForceBranches), extraRef.Hash().String(), ref)))
+		}
+		log.Printf("已额外拉取引用 '%s' (%s)，将随本次操作一并推送。", ref, extraRefType.String())
+	}
+
+	// 设置主引用 (--from-ref) 的推送 RefSpec，追加到已有的 (--push-branches/额外引用) RefSpecs 之后，
+	// 而不是覆盖它们
+	if opts.ToTag != "" { // 如果指定了目标标签，则推送指定的标签
 		// 推送本地 ref 的 hash 到目标标签
-		pushOptions.RefSpecs = []config.RefSpec{
+		pushOptions.RefSpecs = append(pushOptions.RefSpecs,
 			config.RefSpec(fmt.Sprintf("%s:refs/tags/%s", localRef.Hash().String(), opts.ToTag)),
-		}
+		)
 		log.Printf("将本地提交 %s 推送到目标仓库标签 %s。", localRef.Hash().String(), opts.ToTag)
 	} else { // 如果未指定目标标签，则推送所有标签
-		pushOptions.RefSpecs = []config.RefSpec{
+		pushOptions.RefSpecs = append(pushOptions.RefSpecs,
 			config.RefSpec("refs/tags/*:refs/tags/*"), // 推送所有标签
-		}
+		)
 		log.Println("未指定目标标签，将推送所有本地标签到目标仓库。")
 	}
 
+	// 5.4 若指定了 --also-tag，将这些"移动标签" (如 stable、canary，供 serving controller 监听)
+	// 一并强制指向本次晋级的提交，与主标签同一次推送原子生效，不会出现只有版本标签更新、
+	// 移动标签还指向旧提交的中间状态
+	for _, movingTag := range opts.MovingTags {
+		pushOptions.RefSpecs = append(pushOptions.RefSpecs,
+			config.RefSpec(fmt.Sprintf("+%s:refs/tags/%s", localRef.Hash().String(), movingTag)),
+		)
+		log.Printf("将移动标签 '%s' 一并指向提交 %s。", movingTag, localRef.Hash().String())
+	}
+
 	// 6. 执行推送操作
 	log.Printf("正在推送内容到目标仓库 %s...", opts.ToRepoURL)
-	err = gitTarget.Push(pushOptions)
+	err = gitTarget.PushContext(ctx, pushOptions)
 	if err != nil {
 		//if errors.Is(err, git.ErrRemoteExists) {
 		//	// NoPushError 表示没有要推送的新内容，通常不是错误
@@ -197,7 +494,7 @@ func PerformGitOperation(opts GitOperationOptions) error {
 			if tag == "" {
 				tag = opts.FromRef
 			}
-			refType, err = checkRemoteRefExistence(opts.ToRepoURL, tag, opts.ToAuth)
+			refType, err = checkRemoteRefExistence(ctx, opts.ToRepoURL, tag, opts.ToAuth, opts.InsecureSkipTLS, caBundle)
 			if err != nil {
 				return fmt.Errorf("检查标签 '%s' 已存在于目标仓库 发生错误 %v。", tag, err)
 			}
@@ -205,8 +502,12 @@ func PerformGitOperation(opts GitOperationOptions) error {
 				switch opts.OnTagExistsBehavior {
 				case "error":
 					return fmt.Errorf("标签 '%s' 已存在于目标仓库，且配置为报错模式。", tag)
-				case "skip":
-					log.Printf("标签 '%s' 已存在于目标仓库，已跳过推送。", tag)
+				case "skip", "overwrite":
+					// 已是最新 (NoErrAlreadyUpToDate 意味着哈希本就相同)，两种行为在这种情况下等价，都视为成功
+					log.Printf("标签 '%s' 已存在于目标仓库且指向相同提交，无需操作。", tag)
+					if warnErr := opts.warn("tag-skipped", "标签 '%s' 已存在于目标仓库且指向相同提交，已跳过推送", tag); warnErr != nil {
+						return warnErr
+					}
 					return nil // 视为成功，不返回错误
 				default:
 					// 理论上不会发生，因为设置了默认值
@@ -214,6 +515,60 @@ func PerformGitOperation(opts GitOperationOptions) error {
 				}
 			}
 		}
+
+		// 目标标签已存在且指向不同的提交时，go-git 返回 ErrForceNeeded 而不是 NoErrAlreadyUpToDate
+		if errors.Is(err, git.ErrForceNeeded) {
+			tag := opts.ToTag
+			if tag == "" {
+				tag = opts.FromRef
+			}
+			var checkErr error
+			refType, checkErr = checkRemoteRefExistence(ctx, opts.ToRepoURL, tag, opts.ToAuth, opts.InsecureSkipTLS, caBundle)
+			if checkErr != nil {
+				return fmt.Errorf("检查标签 '%s' 已存在于目标仓库 发生错误 %v。", tag, checkErr)
+			}
+			if refType == RefTypeTag {
+				switch opts.OnTagExistsBehavior {
+				case "error":
+					return fmt.Errorf("标签 '%s' 已存在于目标仓库且指向不同提交，且配置为报错模式。", tag)
+				case "skip":
+					log.Printf("标签 '%s' 已存在于目标仓库且指向不同提交，已跳过推送。", tag)
+					if warnErr := opts.warn("tag-skipped", "标签 '%s' 已存在于目标仓库且指向不同提交，已跳过推送", tag); warnErr != nil {
+						return warnErr
+					}
+					return nil
+				case "overwrite":
+					log.Printf("⚠️ 标签 '%s' 已存在于目标仓库且指向不同提交，正在强制覆盖为 %s...", tag, localRef.Hash().String())
+					forcePushOptions := &git.PushOptions{
+						RemoteName:      remoteName,
+						Progress:        opts.ProgressWriter,
+						InsecureSkipTLS: opts.InsecureSkipTLS, // 对应全局 --insecure 标志
+						CABundle:        caBundle,
+						RefSpecs: []config.RefSpec{
+							config.RefSpec(fmt.Sprintf("+%s:refs/tags/%s", localRef.Hash().String(), tag)),
+						},
+					}
+					if opts.ToAuth != nil {
+						forcePushAuth, authErr := opts.ToAuth.GetAuthMethod()
+						if authErr != nil {
+							return fmt.Errorf("解析目标仓库认证方式失败: %w", authErr)
+						}
+						forcePushOptions.Auth = forcePushAuth
+					}
+					if pushErr := gitTarget.PushContext(ctx, forcePushOptions); pushErr != nil && !errors.Is(pushErr, git.NoErrAlreadyUpToDate) {
+						return fmt.Errorf("强制覆盖标签 '%s' 失败: %w", tag, pushErr)
+					}
+					log.Printf("✅ 标签 '%s' 已强制覆盖。", tag)
+					return nil
+				default:
+					return fmt.Errorf("未知的 --on-tag-exists 行为: %s", opts.OnTagExistsBehavior)
+				}
+			} else if !opts.ForceBranches {
+				// 未命中标签分支的处理逻辑，说明是 --push-branches/额外分支引用中的某个分支在目标仓库上不是快进关系，
+				// 本地已通过非强制 RefSpec 让 go-git 提前拦截，避免意外覆盖生产分支历史
+				return fmt.Errorf("推送被拒绝: 部分分支在目标仓库上不是快进关系 (non-fast-forward)，为避免意外覆盖分支历史已中止；如确认需要覆盖，请添加 --force-branches: %w", err)
+			}
+		}
 		return fmt.Errorf("推送失败: %w", err)
 	}
 
@@ -221,9 +576,149 @@ func PerformGitOperation(opts GitOperationOptions) error {
 	return nil
 }
 
-// checkRemoteRefExistence 检查远程仓库中是否存在指定的分支或标签
-// 返回 1 表示是标签，2 表示是分支，-1 表示未找到或未知
-func checkRemoteRefExistence(repoURL, refName string, auth GitAuthMethod) (RefType, error) {
+// ForkSyncOptions 包含将 fork 从其上游同步到最新状态所需的所有参数
+type ForkSyncOptions struct {
+	UpstreamRepoURL string
+	UpstreamAuth    GitAuthMethod
+	ForkRepoURL     string
+	ForkAuth        GitAuthMethod
+	OutputDir       string // 镜像克隆到的本地目录
+	Force           bool   // 为 true 时强制更新 fork 上的分支/标签，即使不是快进 (fast-forward)
+	Atomic          bool   // 为 true 时以原子方式推送所有分支与标签，任意一个被目标拒绝则整体回滚
+	Prune           bool   // 为 true 时，删除目标仓库上源仓库已不存在的分支/标签，实现完整的 --mirror 语义
+	InsecureSkipTLS bool   // 为 true 时跳过 TLS 证书校验，对应全局 --insecure 标志
+	CACertFile      string // 非空时加载该文件作为附加的 CA 证书，用于校验 HTTPS 远程的 TLS 证书
+	ProgressWriter  io.Writer
+	Warnings        *WarningCollector
+	Timeline        *Timeline
+}
+
+func (opts ForkSyncOptions) warn(code, format string, args ...interface{}) error {
+	if opts.Warnings != nil {
+		return opts.Warnings.Add(code, format, args...)
+	}
+	return nil
+}
+
+func (opts ForkSyncOptions) startPhase(name string) {
+	if opts.Timeline != nil {
+		opts.Timeline.StartPhase(name)
+	}
+}
+
+// SyncFork 镜像克隆上游仓库的全部分支与标签，随后原样推送 (fast-forward) 或强制推送 (--force) 到
+// fork 仓库，用于让长期存在的生产 fork 追平已经领先的上游 dev 项目，而不需要逐个分支/标签手动操作。
+// Prune 为 true 时额外删除 fork 仓库上源仓库已不存在的分支/标签，实现完整的 git --mirror 语义。
+// ctx 取消时会中断正在进行的镜像克隆/推送，用于响应 SIGINT/SIGTERM。
+func SyncFork(ctx context.Context, opts ForkSyncOptions) error {
+	caBundle, err := loadCABundle(opts.CACertFile)
+	if err != nil {
+		return err
+	}
+
+	opts.startPhase("mirror-clone")
+	cloneOptions := &git.CloneOptions{
+		URL:             opts.UpstreamRepoURL,
+		Progress:        opts.ProgressWriter,
+		InsecureSkipTLS: opts.InsecureSkipTLS, // 对应全局 --insecure 标志
+		CABundle:        caBundle,
+		Mirror:          true, // 完整镜像所有分支与标签的引用，而非某一条单一引用
+	}
+	if opts.UpstreamAuth != nil {
+		upstreamAuth, err := opts.UpstreamAuth.GetAuthMethod()
+		if err != nil {
+			return fmt.Errorf("解析上游仓库认证方式失败: %w", err)
+		}
+		cloneOptions.Auth = upstreamAuth
+	}
+
+	log.Printf("正在镜像克隆上游仓库 %s 到 %s...", opts.UpstreamRepoURL, opts.OutputDir)
+	r, err := git.PlainCloneContext(ctx, opts.OutputDir, cloneOptions)
+	if err != nil {
+		return fmt.Errorf("镜像克隆上游仓库失败: %w", err)
+	}
+	log.Printf("上游仓库已成功镜像克隆到 %s。", opts.OutputDir)
+
+	opts.startPhase("push")
+	log.Printf("正在配置 fork 远程仓库 %s...", opts.ForkRepoURL)
+	forkRemoteConfig := &config.RemoteConfig{
+		Name: "fork",
+		URLs: []string{opts.ForkRepoURL},
+	}
+	gitFork, err := r.CreateRemote(forkRemoteConfig)
+	if err != nil {
+		return fmt.Errorf("创建 fork 远程仓库配置失败: %w", err)
+	}
+
+	pushOptions := &git.PushOptions{
+		RemoteName:      "fork",
+		Progress:        opts.ProgressWriter,
+		InsecureSkipTLS: opts.InsecureSkipTLS, // 对应全局 --insecure 标志
+		CABundle:        caBundle,
+		Force:           opts.Force,
+		Atomic:          opts.Atomic,
+		Prune:           opts.Prune,
+		RefSpecs: []config.RefSpec{
+			config.RefSpec("refs/heads/*:refs/heads/*"),
+			config.RefSpec("refs/tags/*:refs/tags/*"),
+		},
+	}
+	if opts.ForkAuth != nil {
+		forkAuth, err := opts.ForkAuth.GetAuthMethod()
+		if err != nil {
+			return fmt.Errorf("解析 fork 仓库认证方式失败: %w", err)
+		}
+		pushOptions.Auth = forkAuth
+	}
+
+	log.Printf("正在将所有分支与标签推送到 fork 仓库 %s (force=%t)...", opts.ForkRepoURL, opts.Force)
+	if err := gitFork.PushContext(ctx, pushOptions); err != nil {
+		if strings.Contains(err.Error(), "decode report-status: unknown channel unpack ok") {
+			log.Println("内容已成功推送到 fork 仓库。")
+			return nil
+		}
+		if errors.Is(err, git.NoErrAlreadyUpToDate) {
+			log.Println("fork 仓库已经是最新状态，无需推送。")
+			return nil
+		}
+		if !opts.Force && strings.Contains(err.Error(), "non-fast-forward") {
+			if warnErr := opts.warn("sync-fork-non-fast-forward", "部分分支/标签在 fork 仓库上不是快进关系，已跳过推送，可使用 --force 覆盖: %v", err); warnErr != nil {
+				return warnErr
+			}
+			log.Printf("⚠️ 部分分支/标签不是快进关系，已跳过，可使用 --force 强制覆盖: %v\n", err)
+			return nil
+		}
+		return fmt.Errorf("推送到 fork 仓库失败: %w", err)
+	}
+
+	log.Println("内容已成功推送到 fork 仓库。")
+	return nil
+}
+
+// branchForcePrefix 返回分支推送 RefSpec 的强制前缀："+" 表示强制推送 (跳过快进校验)，
+// 空字符串表示非强制推送 (由 go-git 在本地依据已知的远程分支头拒绝非快进更新)
+func branchForcePrefix(force bool) string {
+	if force {
+		return "+"
+	}
+	return ""
+}
+
+// loadCABundle 在 caCertFile 非空时读取其内容作为附加 CA 证书，为空时返回 nil (不附加)，
+// 与系统证书池一并用于校验 HTTPS 远程的 TLS 证书 (对应 --ca-cert 标志)
+func loadCABundle(caCertFile string) ([]byte, error) {
+	if caCertFile == "" {
+		return nil, nil
+	}
+	bundle, err := os.ReadFile(caCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取 CA 证书文件 '%s' 失败: %w", caCertFile, err)
+	}
+	return bundle, nil
+}
+
+// listRemoteRefs 列出远程仓库的全部分支与标签名称 (不含 refs/heads/、refs/tags/ 前缀)
+func listRemoteRefs(ctx context.Context, repoURL string, auth GitAuthMethod, insecure bool, caBundle []byte) (tags, branches []string, err error) {
 	rem := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
 		Name: "origin",
 		URLs: []string{repoURL},
@@ -233,18 +728,22 @@ func checkRemoteRefExistence(repoURL, refName string, auth GitAuthMethod) (RefTy
 
 	listOptions := &git.ListOptions{
 		PeelingOption:   git.AppendPeeled,
-		InsecureSkipTLS: true,
+		InsecureSkipTLS: insecure,
+		CABundle:        caBundle,
 	}
 	if auth != nil {
-		listOptions.Auth = auth.GetAuthMethod()
+		authMethod, err := auth.GetAuthMethod()
+		if err != nil {
+			return nil, nil, fmt.Errorf("解析 '%s' 的认证方式失败: %w", repoURL, err)
+		}
+		listOptions.Auth = authMethod
 	}
 
-	refs, err := rem.List(listOptions)
+	refs, err := rem.ListContext(ctx, listOptions)
 	if err != nil {
-		return RefTypeUnknown, fmt.Errorf("列出远程引用失败: %w", err)
+		return nil, nil, fmt.Errorf("列出远程引用失败: %w", err)
 	}
 
-	var tags, branches []string
 	for _, ref := range refs {
 		if ref.Name().IsTag() {
 			tags = append(tags, ref.Name().Short())
@@ -252,6 +751,16 @@ func checkRemoteRefExistence(repoURL, refName string, auth GitAuthMethod) (RefTy
 			branches = append(branches, ref.Name().Short())
 		}
 	}
+	return tags, branches, nil
+}
+
+// checkRemoteRefExistence 检查远程仓库中是否存在指定的分支或标签
+// 返回 RefTypeTag (1) 表示是标签，RefTypeBranch (2) 表示是分支，RefTypeUnknown (0) 表示未找到或未知
+func checkRemoteRefExistence(ctx context.Context, repoURL, refName string, auth GitAuthMethod, insecure bool, caBundle []byte) (RefType, error) {
+	tags, branches, err := listRemoteRefs(ctx, repoURL, auth, insecure, caBundle)
+	if err != nil {
+		return RefTypeUnknown, err
+	}
 
 	if slices.Contains(tags, refName) {
 		log.Printf("引用 '%s' 存在于远程仓库并被识别为标签。", refName)
@@ -265,3 +774,60 @@ func checkRemoteRefExistence(repoURL, refName string, auth GitAuthMethod) (RefTy
 	log.Printf("引用 '%s' 在远程仓库中未被识别为标签或分支。", refName)
 	return RefTypeUnknown, nil
 }
+
+// RefSetDiff 描述两个远程仓库分支/标签集合的差异，用于检测已发生漂移的镜像
+type RefSetDiff struct {
+	SourceOnlyBranches []string `json:"source_only_branches,omitempty"`
+	TargetOnlyBranches []string `json:"target_only_branches,omitempty"`
+	SourceOnlyTags     []string `json:"source_only_tags,omitempty"`
+	TargetOnlyTags     []string `json:"target_only_tags,omitempty"`
+}
+
+// Empty 返回两个仓库的引用集合是否完全一致
+func (d *RefSetDiff) Empty() bool {
+	return len(d.SourceOnlyBranches) == 0 && len(d.TargetOnlyBranches) == 0 &&
+		len(d.SourceOnlyTags) == 0 && len(d.TargetOnlyTags) == 0
+}
+
+// stringSetDiff 返回只存在于 a 中、以及只存在于 b 中的元素，均按字典序排序，便于稳定输出
+func stringSetDiff(a, b []string) (onlyInA, onlyInB []string) {
+	setA := make(map[string]struct{}, len(a))
+	for _, v := range a {
+		setA[v] = struct{}{}
+	}
+	setB := make(map[string]struct{}, len(b))
+	for _, v := range b {
+		setB[v] = struct{}{}
+	}
+	for v := range setA {
+		if _, ok := setB[v]; !ok {
+			onlyInA = append(onlyInA, v)
+		}
+	}
+	for v := range setB {
+		if _, ok := setA[v]; !ok {
+			onlyInB = append(onlyInB, v)
+		}
+	}
+	sort.Strings(onlyInA)
+	sort.Strings(onlyInB)
+	return onlyInA, onlyInB
+}
+
+// DiffRemoteRefs 对源仓库与目标仓库分别执行 ls-remote，比较两者的分支/标签集合，
+// 返回只存在于一方的引用列表，用于检测已经产生漂移 (如上游删除了标签但目标仓库仍保留) 的镜像。
+func DiffRemoteRefs(ctx context.Context, sourceRepoURL string, sourceAuth GitAuthMethod, targetRepoURL string, targetAuth GitAuthMethod, insecure bool, caBundle []byte) (*RefSetDiff, error) {
+	sourceTags, sourceBranches, err := listRemoteRefs(ctx, sourceRepoURL, sourceAuth, insecure, caBundle)
+	if err != nil {
+		return nil, fmt.Errorf("列出源仓库 '%s' 的引用失败: %w", sourceRepoURL, err)
+	}
+	targetTags, targetBranches, err := listRemoteRefs(ctx, targetRepoURL, targetAuth, insecure, caBundle)
+	if err != nil {
+		return nil, fmt.Errorf("列出目标仓库 '%s' 的引用失败: %w", targetRepoURL, err)
+	}
+
+	diff := &RefSetDiff{}
+	diff.SourceOnlyBranches, diff.TargetOnlyBranches = stringSetDiff(sourceBranches, targetBranches)
+	diff.SourceOnlyTags, diff.TargetOnlyTags = stringSetDiff(sourceTags, targetTags)
+	return diff, nil
+}
@@ -6,6 +6,7 @@ import (
 	"log" // Using standard log for consistency as requested
 	"net/http"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -46,7 +47,7 @@ func GetKubeConfig() (*rest.Config, error) { // Removed kubeconfigPath parameter
 
 // CheckK8sNamespaceExists 检查给定的 Kubernetes 命名空间是否存在。
 // 它需要一个 Kubernetes REST 配置和一个命名空间名称。
-func CheckK8sNamespaceExists(config *rest.Config, namespace string) (bool, error) {
+func CheckK8sNamespaceExists(ctx context.Context, config *rest.Config, namespace string) (bool, error) {
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return false, fmt.Errorf("创建 Kubernetes 客户端失败: %w", err)
@@ -54,7 +55,7 @@ func CheckK8sNamespaceExists(config *rest.Config, namespace string) (bool, error
 
 	log.Printf("ℹ️ 正在检查 Kubernetes 命名空间 '%s' 是否存在...\n", namespace)
 
-	_, err = clientset.CoreV1().Namespaces().Get(context.Background(), namespace, metav1.GetOptions{})
+	_, err = clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
 	if err != nil {
 		if statusError, isStatusError := err.(*errors.StatusError); isStatusError && statusError.ErrStatus.Code == http.StatusNotFound {
 			log.Printf("ℹ️ Kubernetes 命名空间 '%s' 不存在。\n", namespace)
@@ -68,8 +69,91 @@ func CheckK8sNamespaceExists(config *rest.Config, namespace string) (bool, error
 	return true, nil // Namespace exists
 }
 
+// GetNamespaceAnnotation 读取给定 Kubernetes 命名空间上的一个 annotation，
+// 第二个返回值表示该 annotation 是否存在。
+func GetNamespaceAnnotation(ctx context.Context, config *rest.Config, namespace, key string) (string, bool, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return "", false, fmt.Errorf("创建 Kubernetes 客户端失败: %w", err)
+	}
+
+	ns, err := clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return "", false, fmt.Errorf("获取命名空间 '%s' 失败: %w", namespace, err)
+	}
+
+	value, ok := ns.Annotations[key]
+	return value, ok, nil
+}
+
+// SetNamespaceAnnotation 为给定的 Kubernetes 命名空间设置一个 annotation，
+// 用于记录随 GitLab 项目变化的溯源信息 (如项目当前的完整路径)，避免其漂移出 k8s 侧的记录。
+func SetNamespaceAnnotation(ctx context.Context, config *rest.Config, namespace, key, value string) error {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("创建 Kubernetes 客户端失败: %w", err)
+	}
+
+	ns, err := clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("获取命名空间 '%s' 失败: %w", namespace, err)
+	}
+
+	if ns.Annotations == nil {
+		ns.Annotations = map[string]string{}
+	}
+	ns.Annotations[key] = value
+
+	if _, err := clientset.CoreV1().Namespaces().Update(ctx, ns, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("更新命名空间 '%s' 的 annotation '%s' 失败: %w", namespace, key, err)
+	}
+	log.Printf("✅ 已将命名空间 '%s' 的 annotation '%s' 更新为 '%s'。\n", namespace, key, value)
+	return nil
+}
+
+// EnsureSecret 确保命名空间下的 Secret 中存在指定 key 的非空值：Secret 不存在时创建，
+// 存在但缺少该 key 或该 key 为空时补充写入，key 已有非空值时保持不变 (幂等，不会覆盖已有令牌)。
+// 返回值表示是否发生了写入。
+func EnsureSecret(ctx context.Context, config *rest.Config, namespace, secretName, key, value string) (bool, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return false, fmt.Errorf("创建 Kubernetes 客户端失败: %w", err)
+	}
+
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return false, fmt.Errorf("获取命名空间 '%s' 中的 Secret '%s' 失败: %w", namespace, secretName, err)
+		}
+		newSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace},
+			Data:       map[string][]byte{key: []byte(value)},
+		}
+		if _, err := clientset.CoreV1().Secrets(namespace).Create(ctx, newSecret, metav1.CreateOptions{}); err != nil {
+			return false, fmt.Errorf("在命名空间 '%s' 中创建 Secret '%s' 失败: %w", namespace, secretName, err)
+		}
+		log.Printf("✅ 已在命名空间 '%s' 中创建 Secret '%s'。\n", namespace, secretName)
+		return true, nil
+	}
+
+	if existing, ok := secret.Data[key]; ok && len(existing) > 0 {
+		log.Printf("ℹ️ 命名空间 '%s' 中的 Secret '%s' 已包含非空 key '%s'，跳过写入。\n", namespace, secretName, key)
+		return false, nil
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[key] = []byte(value)
+	if _, err := clientset.CoreV1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return false, fmt.Errorf("更新命名空间 '%s' 中的 Secret '%s' 失败: %w", namespace, secretName, err)
+	}
+	log.Printf("✅ 已为命名空间 '%s' 中的 Secret '%s' 补充写入 key '%s'。\n", namespace, secretName, key)
+	return true, nil
+}
+
 // GetSecretValue 从 Kubernetes Secret 中获取指定 key 的值
-func GetSecretValue(kubeConfig *rest.Config, namespace string, secretName string, key string) (string, error) {
+func GetSecretValue(ctx context.Context, kubeConfig *rest.Config, namespace string, secretName string, key string) (string, error) {
 	clientset, err := kubernetes.NewForConfig(kubeConfig)
 	if err != nil {
 		return "", fmt.Errorf("创建 Kubernetes 客户端失败: %w", err)
@@ -78,7 +162,7 @@ func GetSecretValue(kubeConfig *rest.Config, namespace string, secretName string
 	log.Printf("ℹ️ 正在从 Kubernetes Secret 中获取令牌。命名空间: %s, Secret名称: %s, Key: %s\n",
 		namespace, secretName, key)
 
-	secret, err := clientset.CoreV1().Secrets(namespace).Get(context.Background(), secretName, metav1.GetOptions{})
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
 	if err != nil {
 		return "", fmt.Errorf("无法在命名空间 '%s' 中获取 Secret '%s': %w", namespace, secretName, err)
 	}
@@ -93,3 +177,30 @@ func GetSecretValue(kubeConfig *rest.Config, namespace string, secretName string
 
 	return string(tokenBytes), nil
 }
+
+// GetConfigMapValue 从指定命名空间下的 ConfigMap 中读取一个 key 的值，用于 "configmap://<namespace>/<name>/<key>"
+// 来源的 manifest/config 读取，让 GitOps 系统可以直接把已挂载的 ConfigMap 作为输入而无需落地临时文件。
+func GetConfigMapValue(ctx context.Context, kubeConfig *rest.Config, namespace, name, key string) (string, error) {
+	clientset, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		return "", fmt.Errorf("创建 Kubernetes 客户端失败: %w", err)
+	}
+
+	log.Printf("ℹ️ 正在从 Kubernetes ConfigMap 中读取内容。命名空间: %s, ConfigMap名称: %s, Key: %s\n",
+		namespace, name, key)
+
+	configMap, err := clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("无法在命名空间 '%s' 中获取 ConfigMap '%s': %w", namespace, name, err)
+	}
+
+	value, ok := configMap.Data[key]
+	if !ok {
+		return "", fmt.Errorf("ConfigMap '%s' 中不存在 key '%s'", name, key)
+	}
+
+	log.Printf("✅ 成功从 Kubernetes ConfigMap 读取内容。命名空间: %s, ConfigMap名称: %s\n",
+		namespace, name)
+
+	return value, nil
+}
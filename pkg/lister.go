@@ -0,0 +1,56 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// ListGroupProjectsRequest 描述一次组项目枚举所需的全部输入。调用方需自行完成令牌解析、
+// 排除规则/输出格式等 CLI 相关的前置与后续处理，本函数只负责调用 GitLab API 分页拉取，
+// 不依赖 k8s、cobra 或 log.Fatal，因此既可以被 CLI 调用，也可以被其他宿主直接复用。
+type ListGroupProjectsRequest struct {
+	Client     *gitlab.Client
+	GroupPath  string
+	Visibility gitlab.VisibilityValue // 为空字符串时不按可见性筛选
+}
+
+// ListGroupProjects 逐页拉取 GroupPath 下的全部项目 (含子组)，对每一页中的每个项目依次调用
+// onProject，不在内存中累积全量结果，避免大型实例下内存随组规模线性增长。onProject 返回
+// non-nil error 时立即中止分页并将该 error 原样返回给调用方。
+func ListGroupProjects(ctx context.Context, req ListGroupProjectsRequest, onProject func(*gitlab.Project) error) error {
+	if req.Client == nil {
+		return fmt.Errorf("ListGroupProjectsRequest.Client 不能为空")
+	}
+
+	listOptions := &gitlab.ListGroupProjectsOptions{}
+	listOptions.PerPage = 100
+	listOptions.IncludeSubGroups = gitlab.Ptr(true)
+	if req.Visibility != "" {
+		listOptions.Visibility = gitlab.Ptr(req.Visibility)
+	}
+
+	for {
+		projects, resp, err := req.Client.Groups.ListGroupProjects(req.GroupPath, listOptions, gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("列出组 '%s' 的项目失败: %w", req.GroupPath, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("列出组 '%s' 的项目失败，HTTP 状态码: %d", req.GroupPath, resp.StatusCode)
+		}
+
+		for _, p := range projects {
+			if err := onProject(p); err != nil {
+				return err
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		listOptions.Page = resp.NextPage
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PushRunOutcome 将一次 CLI 运行的结果 (成功/失败、耗时) 以 Prometheus 文本暴露格式
+// 推送到 gatewayURL 指向的 Pushgateway，用于一次性 CLI/CronJob 场景下积累成功率看板
+// (这类调用没有常驻进程可供 /metrics 端点抓取，只能反过来推送)。
+//
+// 未直接依赖 prometheus/client_golang，而是手写文本格式：这是 Pushgateway 自身的标准
+// 输入格式 (见 https://github.com/prometheus/pushgateway#command-line)，手写体积更小，
+// 也避免为这一个功能引入整个客户端库依赖。
+func PushRunOutcome(gatewayURL, job string, success bool, duration time.Duration) error {
+	if gatewayURL == "" || job == "" {
+		return fmt.Errorf("推送运行指标需要同时提供 gatewayURL 与 job")
+	}
+
+	successValue := 0
+	if success {
+		successValue = 1
+	}
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "# TYPE gitlab_fork_cli_run_duration_seconds gauge\n")
+	fmt.Fprintf(&body, "gitlab_fork_cli_run_duration_seconds %f\n", duration.Seconds())
+	fmt.Fprintf(&body, "# TYPE gitlab_fork_cli_run_success gauge\n")
+	fmt.Fprintf(&body, "gitlab_fork_cli_run_success %d\n", successValue)
+
+	url := strings.TrimRight(gatewayURL, "/") + "/metrics/job/" + job
+	req, err := http.NewRequest(http.MethodPut, url, &body)
+	if err != nil {
+		return fmt.Errorf("构造 Pushgateway 请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("推送指标到 Pushgateway '%s' 失败: %w", gatewayURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("推送指标到 Pushgateway '%s' 失败: 状态码 %d", gatewayURL, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// PromotionRecord 描述一次成功回流到目标项目的记录，用于事后快速定位该目标项目
+// 最后一次已知良好 (last-known-good) 的标签，供 'last-good' 命令查询、辅助事故回滚决策。
+type PromotionRecord struct {
+	Group      string    `json:"group"`
+	Project    string    `json:"project"`
+	Tag        string    `json:"tag"`
+	Reason     string    `json:"reason"`
+	PromotedAt time.Time `json:"promotedAt"`
+}
+
+func promotionKey(group, project string) string {
+	return group + "/" + project
+}
+
+// DefaultPromotionStorePath 返回本地记录晋级历史的默认文件路径 (~/.gitlab-fork-cli/promotions.json)，
+// 与 loadGlobalConfigDefaults 使用的 ~/.gitlab-fork-cli.yaml 同级，但记录的是运行时状态而非配置。
+func DefaultPromotionStorePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("无法确定用户主目录: %w", err)
+	}
+	return filepath.Join(home, ".gitlab-fork-cli", "promotions.json"), nil
+}
+
+// PromotionStore 是按 (group, project) 索引、记录每个目标项目最后一次成功晋级的本地 JSON 状态文件。
+type PromotionStore struct {
+	path string
+}
+
+// NewPromotionStore 创建一个持久化到 path 的晋级状态存储
+func NewPromotionStore(path string) *PromotionStore {
+	return &PromotionStore{path: path}
+}
+
+func (s *PromotionStore) load() (map[string]PromotionRecord, error) {
+	records := map[string]PromotionRecord{}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取状态文件 '%s' 失败: %w", s.path, err)
+	}
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("解析状态文件 '%s' 失败: %w", s.path, err)
+	}
+	return records, nil
+}
+
+// RecordSuccess 记录一次成功的晋级，覆盖同一 (group, project) 之前的记录
+func (s *PromotionStore) RecordSuccess(group, project, tag, reason string, promotedAt time.Time) error {
+	records, err := s.load()
+	if err != nil {
+		return err
+	}
+	records[promotionKey(group, project)] = PromotionRecord{
+		Group:      group,
+		Project:    project,
+		Tag:        tag,
+		Reason:     reason,
+		PromotedAt: promotedAt,
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("创建状态目录失败: %w", err)
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化状态文件失败: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("写入状态文件 '%s' 失败: %w", s.path, err)
+	}
+	return nil
+}
+
+// LastGood 返回 (group, project) 最后一次成功晋级的记录；不存在时 ok 为 false
+func (s *PromotionStore) LastGood(group, project string) (PromotionRecord, bool, error) {
+	records, err := s.load()
+	if err != nil {
+		return PromotionRecord{}, false, err
+	}
+	rec, ok := records[promotionKey(group, project)]
+	return rec, ok, nil
+}
+
+// All 返回全部记录，按 group/project 排序，供 'last-good' 不带参数时列出全部目标项目
+func (s *PromotionStore) All() ([]PromotionRecord, error) {
+	records, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]PromotionRecord, 0, len(records))
+	for _, r := range records {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Group != list[j].Group {
+			return list[i].Group < list[j].Group
+		}
+		return list[i].Project < list[j].Project
+	})
+	return list, nil
+}
@@ -0,0 +1,72 @@
+package pkg
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// PushRules 是从目标项目的 GitLab push rules 中挑选出的、可以在推送前于本地校验的子集
+// (最大文件大小、commit message 正则、是否要求已签名提交)，其余规则 (如 member_check)
+// 依赖 GitLab 服务端状态，无法在本地重现，因此不在此结构体中体现。
+type PushRules struct {
+	CommitMessageRegex         string // 非空时，commit message 必须匹配该正则
+	CommitMessageNegativeRegex string // 非空时，commit message 不能匹配该正则
+	MaxFileSize                int    // 单位 MB，为 0 表示不限制
+	RejectUnsignedCommits      bool   // 为 true 时要求提交携带签名
+}
+
+// ValidatePushRules 在本地对即将推送的单个提交 (及其内容) 校验目标项目的 push rules，
+// 返回违反的规则描述列表 (为空表示未发现问题)，用于在推送前就把问题报出来，
+// 而不是让使用者去解码 GitLab pre-receive hook 返回的晦涩错误。
+func ValidatePushRules(r *git.Repository, commitHash plumbing.Hash, rules PushRules) ([]string, error) {
+	commit, err := r.CommitObject(commitHash)
+	if err != nil {
+		return nil, fmt.Errorf("读取提交 %s 失败: %w", commitHash.String(), err)
+	}
+
+	var violations []string
+
+	if rules.CommitMessageRegex != "" {
+		re, err := regexp.Compile(rules.CommitMessageRegex)
+		if err != nil {
+			return nil, fmt.Errorf("目标项目的 commit_message_regex '%s' 不是合法的正则表达式: %w", rules.CommitMessageRegex, err)
+		}
+		if !re.MatchString(commit.Message) {
+			violations = append(violations, fmt.Sprintf("提交 %s 的 message 不匹配目标项目要求的 commit_message_regex '%s'", commit.Hash.String()[:8], rules.CommitMessageRegex))
+		}
+	}
+	if rules.CommitMessageNegativeRegex != "" {
+		re, err := regexp.Compile(rules.CommitMessageNegativeRegex)
+		if err != nil {
+			return nil, fmt.Errorf("目标项目的 commit_message_negative_regex '%s' 不是合法的正则表达式: %w", rules.CommitMessageNegativeRegex, err)
+		}
+		if re.MatchString(commit.Message) {
+			violations = append(violations, fmt.Sprintf("提交 %s 的 message 命中了目标项目禁止的 commit_message_negative_regex '%s'", commit.Hash.String()[:8], rules.CommitMessageNegativeRegex))
+		}
+	}
+	if rules.RejectUnsignedCommits && commit.PGPSignature == "" {
+		violations = append(violations, fmt.Sprintf("提交 %s 未携带签名，而目标项目要求 reject_unsigned_commits", commit.Hash.String()[:8]))
+	}
+	if rules.MaxFileSize > 0 {
+		limit := int64(rules.MaxFileSize) * 1024 * 1024
+		files, err := commit.Files()
+		if err != nil {
+			return nil, fmt.Errorf("遍历提交 %s 的文件失败: %w", commit.Hash.String()[:8], err)
+		}
+		err = files.ForEach(func(f *object.File) error {
+			if f.Size > limit {
+				violations = append(violations, fmt.Sprintf("文件 '%s' 大小 %d 字节超过目标项目 max_file_size 限制 (%d MB)", f.Name, f.Size, rules.MaxFileSize))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("遍历提交 %s 的文件失败: %w", commit.Hash.String()[:8], err)
+		}
+	}
+
+	return violations, nil
+}
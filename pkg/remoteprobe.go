@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"github.com/go-git/go-git/v6/storage/memory"
+)
+
+// ProbeRemote 在推送前验证目标仓库可达、鉴权通过，并在 requireEmpty 为 true 时额外验证目标仓库为空，
+// 对 DNS/TLS/鉴权/仓库不存在等常见故障返回可读的具体错误，而不是让使用者去解读一次推送失败的通用报错。
+// ctx 取消时会中断正在进行的远程引用列举，用于响应 SIGINT/SIGTERM。
+func ProbeRemote(ctx context.Context, repoURL string, auth GitAuthMethod, requireEmpty bool, insecure bool, caBundle []byte) error {
+	rem := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "probe",
+		URLs: []string{repoURL},
+	})
+
+	listOptions := &git.ListOptions{InsecureSkipTLS: insecure, CABundle: caBundle}
+	if auth != nil {
+		authMethod, err := auth.GetAuthMethod()
+		if err != nil {
+			return fmt.Errorf("解析 '%s' 的认证方式失败: %w", repoURL, err)
+		}
+		listOptions.Auth = authMethod
+	}
+
+	refs, err := rem.ListContext(ctx, listOptions)
+	if err != nil {
+		switch {
+		case errors.Is(err, transport.ErrRepositoryNotFound):
+			return fmt.Errorf("目标仓库 '%s' 不存在或不可访问: %w", repoURL, err)
+		case errors.Is(err, transport.ErrAuthenticationRequired):
+			return fmt.Errorf("目标仓库 '%s' 需要认证，请提供有效的令牌: %w", repoURL, err)
+		case errors.Is(err, transport.ErrAuthorizationFailed):
+			return fmt.Errorf("目标仓库 '%s' 鉴权失败，令牌可能无效或没有写权限: %w", repoURL, err)
+		default:
+			var dnsErr *net.DNSError
+			if errors.As(err, &dnsErr) {
+				return fmt.Errorf("无法解析目标仓库 '%s' 的域名 (DNS 错误): %w", repoURL, err)
+			}
+			var certErr x509.UnknownAuthorityError
+			if errors.As(err, &certErr) {
+				return fmt.Errorf("目标仓库 '%s' 的 TLS 证书校验失败，可考虑使用 --insecure (⚠️ 慎用): %w", repoURL, err)
+			}
+			return fmt.Errorf("无法连接目标仓库 '%s': %w", repoURL, err)
+		}
+	}
+
+	if requireEmpty && len(refs) > 0 {
+		return fmt.Errorf("目标仓库 '%s' 非空 (已存在 %d 个引用)，而 --require-empty-target 要求目标仓库为空", repoURL, len(refs))
+	}
+
+	return nil
+}
@@ -0,0 +1,336 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ForkManifestSchema 描述 'fork --manifest' 批量派生清单的格式：一个由条目组成的数组，
+// schema 只要求每个条目提供 source-group 与 target-group；source-project 在 schema 层面可省略，
+// 但 'fork --manifest' 会把缺少 source-project 的条目记为失败，不做任何默认推断。
+const ForkManifestSchema = `{
+  "type": "array",
+  "items": {
+    "type": "object",
+    "properties": {
+      "source-group": {"type": "string", "minLength": 1},
+      "source-project": {"type": "string"},
+      "target-group": {"type": "string", "minLength": 1}
+    },
+    "required": ["source-group", "target-group"],
+    "additionalProperties": false
+  }
+}`
+
+// MirrorManifestSchema 描述 'mirror --manifest' 批量镜像清单的格式：一个由条目组成的数组，
+// 每个条目的 from/to 各自要求 *-repo-url 与 *-project 二选一 (与 mirror 命令单次调用时的寻址
+// 约定一致)，此处仅约束字段集合，二选一的取值校验在 loadMirrorManifest 中按条目完成。
+const MirrorManifestSchema = `{
+  "type": "array",
+  "items": {
+    "type": "object",
+    "properties": {
+      "from-repo-url": {"type": "string"},
+      "from-project": {"type": "string"},
+      "to-repo-url": {"type": "string"},
+      "to-project": {"type": "string"}
+    },
+    "additionalProperties": false
+  }
+}`
+
+// BulkDeleteManifestSchema 描述 'bulk-delete --manifest' 批量删除清单的格式
+const BulkDeleteManifestSchema = `{
+  "type": "array",
+  "items": {
+    "type": "object",
+    "properties": {
+      "group": {"type": "string", "minLength": 1},
+      "project": {"type": "string", "minLength": 1},
+      "tag": {"type": "string"}
+    },
+    "required": ["group", "project"],
+    "additionalProperties": false
+  }
+}`
+
+// SettingsProfileSchema 描述 'fork --settings-profile' 使用的选择性设置复制策略文件的格式：
+// 各类别均为可选布尔开关，省略的类别按 false (不复制) 处理
+const SettingsProfileSchema = `{
+  "type": "object",
+  "properties": {
+    "variables": {"type": "boolean"},
+    "protections": {"type": "boolean"},
+    "webhooks": {"type": "boolean"},
+    "members": {"type": "boolean"},
+    "badges": {"type": "boolean"},
+    "metadata": {"type": "boolean"}
+  },
+  "additionalProperties": false
+}`
+
+// GlobalConfigSchema 描述 ~/.gitlab-fork-cli.yaml (或 --config 指定的文件) 的格式
+const GlobalConfigSchema = `{
+  "type": "object",
+  "properties": {
+    "baseUrl": {"type": "string", "pattern": "^https?://"},
+    "secretName": {"type": "string"},
+    "secretKey": {"type": "string"},
+    "modelsGroup": {"type": "string"}
+  },
+  "additionalProperties": false
+}`
+
+// ConfigProfileSchema 描述 'config validate --profile' 使用的环境配置文件格式
+const ConfigProfileSchema = `{
+  "type": "object",
+  "properties": {
+    "baseUrl": {"type": "string", "minLength": 1, "pattern": "^https?://"},
+    "groups": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string", "minLength": 1},
+          "secretName": {"type": "string"},
+          "secretKey": {"type": "string"}
+        },
+        "required": ["name"],
+        "additionalProperties": false
+      }
+    }
+  },
+  "required": ["baseUrl", "groups"],
+  "additionalProperties": false
+}`
+
+// Schemas 按名称汇总本工具所有已发布的 JSON Schema，供 'schema' 子命令打印，
+// 也是 ValidateAgainstSchema 的名称到内容的映射来源。
+var Schemas = map[string]string{
+	"fork-manifest":        ForkManifestSchema,
+	"mirror-manifest":      MirrorManifestSchema,
+	"bulk-delete-manifest": BulkDeleteManifestSchema,
+	"global-config":        GlobalConfigSchema,
+	"config-profile":       ConfigProfileSchema,
+	"settings-profile":     SettingsProfileSchema,
+}
+
+// SchemaNames 返回 Schemas 中全部已注册的名称，按字典序排列，供不带参数的 'schema' 命令输出稳定的顺序。
+func SchemaNames() []string {
+	names := make([]string, 0, len(Schemas))
+	for name := range Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// schemaError 记录一处 schema 校验失败，Path 是指向违规位置的 JSON Pointer 风格路径 (如 "/0/target-group")，
+// 根对象自身的问题路径为空字符串。
+type schemaError struct {
+	Path    string
+	Message string
+}
+
+// SchemaValidationError 汇总一次校验中发现的全部 schemaError，Error() 输出时按路径逐行列出，
+// 使配置/manifest 中的多处问题可以一次性暴露，而不必逐个修复、逐个重跑。
+type SchemaValidationError struct {
+	errs []schemaError
+}
+
+func (e *SchemaValidationError) Error() string {
+	lines := make([]string, len(e.errs))
+	for i, se := range e.errs {
+		path := se.Path
+		if path == "" {
+			path = "(root)"
+		}
+		lines[i] = fmt.Sprintf("%s: %s", path, se.Message)
+	}
+	return strings.Join(lines, "; ")
+}
+
+func (e *SchemaValidationError) add(path, format string, args ...interface{}) {
+	e.errs = append(e.errs, schemaError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// jsonSchemaNode 是内置 JSON Schema 校验器识别的子集：type/properties/required/items/
+// additionalProperties/enum/pattern/minLength/maxLength/minimum/maximum，足以覆盖本工具
+// manifest/config 格式的常见约束，不追求实现完整的 JSON Schema 规范。
+type jsonSchemaNode struct {
+	Type                 string                     `json:"type"`
+	Properties           map[string]*jsonSchemaNode `json:"properties"`
+	Required             []string                   `json:"required"`
+	Items                *jsonSchemaNode            `json:"items"`
+	AdditionalProperties *bool                      `json:"additionalProperties"`
+	Enum                 []interface{}              `json:"enum"`
+	Pattern              string                     `json:"pattern"`
+	MinLength            *int                       `json:"minLength"`
+	MaxLength            *int                       `json:"maxLength"`
+	Minimum              *float64                   `json:"minimum"`
+	Maximum              *float64                   `json:"maximum"`
+}
+
+// ValidateAgainstSchema 按名称 (Schemas 中注册的 key) 校验一份已解析为通用 Go 值的数据
+// (通常来自 yaml.Unmarshal 到 interface{})，返回汇总了全部违规位置的 *SchemaValidationError，
+// 全部通过时返回 nil。schemaName 未注册时返回 error 而不是 panic。
+func ValidateAgainstSchema(schemaName string, data interface{}) error {
+	schemaJSON, ok := Schemas[schemaName]
+	if !ok {
+		return fmt.Errorf("未知的 schema 名称 '%s'，可用名称: %s", schemaName, strings.Join(SchemaNames(), ", "))
+	}
+	var node jsonSchemaNode
+	if err := json.Unmarshal([]byte(schemaJSON), &node); err != nil {
+		return fmt.Errorf("内置 schema '%s' 本身不是合法 JSON，这是工具的 bug: %w", schemaName, err)
+	}
+
+	result := &SchemaValidationError{}
+	validateNode(&node, normalizeForValidation(data), "", result)
+	if len(result.errs) > 0 {
+		return result
+	}
+	return nil
+}
+
+// normalizeForValidation 将 yaml.v3 解析出的 map[string]interface{}/[]interface{} 树原样保留，
+// 因为 yaml.v3 (与 encoding/json 一致) 已经用 map[string]interface{} 表示对象，无需额外转换。
+func normalizeForValidation(v interface{}) interface{} {
+	return v
+}
+
+func validateNode(schema *jsonSchemaNode, value interface{}, path string, result *SchemaValidationError) {
+	if schema == nil {
+		return
+	}
+
+	if value == nil {
+		return // 缺省字段的必填校验由 required 处理，此处不对 null 值本身报错
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			result.add(path, "期望类型为 object，实际为 %s", describeType(value))
+			return
+		}
+		for _, req := range schema.Required {
+			if _, present := obj[req]; !present {
+				result.add(joinPath(path, req), "缺少必填字段")
+			}
+		}
+		for key, val := range obj {
+			propSchema, known := schema.Properties[key]
+			if !known {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					result.add(joinPath(path, key), "不是 schema 中定义的字段 (additionalProperties: false)")
+				}
+				continue
+			}
+			validateNode(propSchema, val, joinPath(path, key), result)
+		}
+
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			result.add(path, "期望类型为 array，实际为 %s", describeType(value))
+			return
+		}
+		for i, item := range arr {
+			validateNode(schema.Items, item, fmt.Sprintf("%s/%d", path, i), result)
+		}
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			result.add(path, "期望类型为 string，实际为 %s", describeType(value))
+			return
+		}
+		if schema.MinLength != nil && len(str) < *schema.MinLength {
+			result.add(path, "长度 %d 小于 minLength (%d)", len(str), *schema.MinLength)
+		}
+		if schema.MaxLength != nil && len(str) > *schema.MaxLength {
+			result.add(path, "长度 %d 大于 maxLength (%d)", len(str), *schema.MaxLength)
+		}
+		if schema.Pattern != "" {
+			matched, err := regexp.MatchString(schema.Pattern, str)
+			if err != nil {
+				result.add(path, "schema 中的 pattern '%s' 不是合法的正则表达式: %v", schema.Pattern, err)
+			} else if !matched {
+				result.add(path, "值 '%s' 不匹配 pattern '%s'", str, schema.Pattern)
+			}
+		}
+
+	case "number", "integer":
+		num, ok := toFloat64(value)
+		if !ok {
+			result.add(path, "期望类型为 %s，实际为 %s", schema.Type, describeType(value))
+			return
+		}
+		if schema.Type == "integer" && num != float64(int64(num)) {
+			result.add(path, "期望为整数，实际为 %v", num)
+		}
+		if schema.Minimum != nil && num < *schema.Minimum {
+			result.add(path, "值 %v 小于 minimum (%v)", num, *schema.Minimum)
+		}
+		if schema.Maximum != nil && num > *schema.Maximum {
+			result.add(path, "值 %v 大于 maximum (%v)", num, *schema.Maximum)
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			result.add(path, "期望类型为 boolean，实际为 %s", describeType(value))
+			return
+		}
+	}
+
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		result.add(path, "值 %v 不在允许的枚举范围内: %v", value, schema.Enum)
+	}
+}
+
+func enumContains(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if fmt.Sprintf("%v", e) == fmt.Sprintf("%v", value) {
+			return true
+		}
+	}
+	return false
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func describeType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64, int, int64:
+		return "number"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func joinPath(base, key string) string {
+	return base + "/" + key
+}
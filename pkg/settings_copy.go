@@ -0,0 +1,268 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// SettingsProfile 描述 'fork --settings-profile' 要从源项目复制到新派生项目的设置类别，
+// 取代此前一个个新增的 --copy-xxx 布尔标志：新增一个类别只需要在此结构体与对应的 Copy*
+// 函数中各加一处，而不必再为每个类别单独设计一个命令行标志。
+type SettingsProfile struct {
+	Variables   bool `yaml:"variables"`
+	Protections bool `yaml:"protections"`
+	Webhooks    bool `yaml:"webhooks"`
+	Members     bool `yaml:"members"`
+	Badges      bool `yaml:"badges"`
+	Metadata    bool `yaml:"metadata"`
+}
+
+// Apply 依次执行 profile 中启用的类别对应的复制操作，任一类别失败都会通过 warnings 记录为
+// 警告 (与 topics/徽章/环境等现有派生后步骤一致)，不会中断其余类别的复制。
+func (p SettingsProfile) Apply(ctx context.Context, client *gitlab.Client, fromProjectID, toProjectID int, warnings *WarningCollector) error {
+	steps := []struct {
+		enabled bool
+		code    string
+		label   string
+		fn      func(context.Context, *gitlab.Client, int, int) error
+	}{
+		{p.Variables, "settings-profile-variables-failed", "CI/CD 变量", CopyProjectVariables},
+		{p.Protections, "settings-profile-protections-failed", "受保护分支", CopyProtectedBranches},
+		{p.Webhooks, "settings-profile-webhooks-failed", "webhooks", CopyWebhooks},
+		{p.Members, "settings-profile-members-failed", "成员", CopyMembers},
+		{p.Badges, "settings-profile-badges-failed", "徽章", CopyProjectBadges},
+		{p.Metadata, "settings-profile-metadata-failed", "元数据 (描述/topics)", CopyMetadata},
+	}
+	for _, step := range steps {
+		if !step.enabled {
+			continue
+		}
+		if err := step.fn(ctx, client, fromProjectID, toProjectID); err != nil {
+			if wrapErr := recordOrReturn(warnings, step.code, "复制%s失败: %v", step.label, err); wrapErr != nil {
+				return wrapErr
+			}
+		}
+	}
+	return nil
+}
+
+// CopyProjectVariables 将源项目的全部 CI/CD 变量原样复制到目标项目 (已存在同名变量时跳过，
+// 不覆盖目标项目已有的配置)
+func CopyProjectVariables(ctx context.Context, client *gitlab.Client, fromProjectID, toProjectID int) error {
+	return CopyProjectVariablesFiltered(ctx, client, fromProjectID, toProjectID, false, false)
+}
+
+// CopyProjectVariablesFiltered 与 CopyProjectVariables 相同，但可选跳过受保护/masked 变量：
+// 受保护变量通常只应在其原本被保护的分支/环境上使用，masked 变量的取值往往是密钥本身，
+// 两者在"复制到一个新派生项目"的场景下并不总是安全或有意义，因此单独暴露为可选项。
+func CopyProjectVariablesFiltered(ctx context.Context, client *gitlab.Client, fromProjectID, toProjectID int, skipProtected, skipMasked bool) error {
+	variables, _, err := client.ProjectVariables.ListVariables(fromProjectID, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("列出源项目 (ID: %d) 的 CI/CD 变量失败: %w", fromProjectID, err)
+	}
+	for _, v := range variables {
+		if skipProtected && v.Protected {
+			continue
+		}
+		if skipMasked && v.Masked {
+			continue
+		}
+		_, _, err := client.ProjectVariables.CreateVariable(toProjectID, &gitlab.CreateProjectVariableOptions{
+			Key:              gitlab.Ptr(v.Key),
+			Value:            gitlab.Ptr(v.Value),
+			VariableType:     gitlab.Ptr(v.VariableType),
+			Protected:        gitlab.Ptr(v.Protected),
+			Masked:           gitlab.Ptr(v.Masked),
+			EnvironmentScope: gitlab.Ptr(v.EnvironmentScope),
+		}, gitlab.WithContext(ctx))
+		if err != nil {
+			if errResp, ok := err.(*gitlab.ErrorResponse); ok && errResp.Response != nil && errResp.Response.StatusCode == 400 {
+				continue // 目标项目已存在同名变量 (同一环境作用域)，跳过而不覆盖
+			}
+			return fmt.Errorf("在目标项目 (ID: %d) 创建变量 '%s' 失败: %w", toProjectID, v.Key, err)
+		}
+	}
+	return nil
+}
+
+// CopyProtectedBranches 将源项目的受保护分支规则复制到目标项目
+func CopyProtectedBranches(ctx context.Context, client *gitlab.Client, fromProjectID, toProjectID int) error {
+	branches, _, err := client.ProtectedBranches.ListProtectedBranches(fromProjectID, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("列出源项目 (ID: %d) 的受保护分支失败: %w", fromProjectID, err)
+	}
+	for _, b := range branches {
+		opt := &gitlab.ProtectRepositoryBranchesOptions{
+			Name: gitlab.Ptr(b.Name),
+		}
+		if len(b.PushAccessLevels) > 0 {
+			opt.PushAccessLevel = gitlab.Ptr(b.PushAccessLevels[0].AccessLevel)
+		}
+		if len(b.MergeAccessLevels) > 0 {
+			opt.MergeAccessLevel = gitlab.Ptr(b.MergeAccessLevels[0].AccessLevel)
+		}
+		if _, _, err := client.ProtectedBranches.ProtectRepositoryBranches(toProjectID, opt, gitlab.WithContext(ctx)); err != nil {
+			return fmt.Errorf("在目标项目 (ID: %d) 保护分支 '%s' 失败: %w", toProjectID, b.Name, err)
+		}
+	}
+	return nil
+}
+
+// CopyProtectedTags 将源项目的受保护标签规则复制到目标项目
+func CopyProtectedTags(ctx context.Context, client *gitlab.Client, fromProjectID, toProjectID int) error {
+	tags, _, err := client.ProtectedTags.ListProtectedTags(fromProjectID, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("列出源项目 (ID: %d) 的受保护标签失败: %w", fromProjectID, err)
+	}
+	for _, t := range tags {
+		opt := &gitlab.ProtectRepositoryTagsOptions{Name: gitlab.Ptr(t.Name)}
+		if len(t.CreateAccessLevels) > 0 {
+			opt.CreateAccessLevel = gitlab.Ptr(t.CreateAccessLevels[0].AccessLevel)
+		}
+		if _, _, err := client.ProtectedTags.ProtectRepositoryTags(toProjectID, opt, gitlab.WithContext(ctx)); err != nil {
+			return fmt.Errorf("在目标项目 (ID: %d) 保护标签 '%s' 失败: %w", toProjectID, t.Name, err)
+		}
+	}
+	return nil
+}
+
+// CopyWebhooks 将源项目的 webhooks 复制到目标项目。GitLab API 不会把 webhook 的密钥 token
+// 读出来 (创建时一次性写入)，因此复制出的 webhook 默认没有 token；如果需要保留 token，
+// 使用 CopyWebhooksWithSecrets 并提供一份按 URL 映射 token 的文件。
+func CopyWebhooks(ctx context.Context, client *gitlab.Client, fromProjectID, toProjectID int) error {
+	return CopyWebhooksWithSecrets(ctx, client, fromProjectID, toProjectID, nil)
+}
+
+// CopyWebhooksWithSecrets 与 CopyWebhooks 相同，但对 secretsByURL 中列出的 URL 使用对应的 token
+// 值创建 webhook (键为源项目 webhook 的 URL)，用于弥补 API 无法读回已配置 token 的限制。
+func CopyWebhooksWithSecrets(ctx context.Context, client *gitlab.Client, fromProjectID, toProjectID int, secretsByURL map[string]string) error {
+	hooks, _, err := client.Projects.ListProjectHooks(fromProjectID, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("列出源项目 (ID: %d) 的 webhooks 失败: %w", fromProjectID, err)
+	}
+	for _, h := range hooks {
+		opt := &gitlab.AddProjectHookOptions{
+			URL:                   gitlab.Ptr(h.URL),
+			PushEvents:            gitlab.Ptr(h.PushEvents),
+			MergeRequestsEvents:   gitlab.Ptr(h.MergeRequestsEvents),
+			TagPushEvents:         gitlab.Ptr(h.TagPushEvents),
+			PipelineEvents:        gitlab.Ptr(h.PipelineEvents),
+			EnableSSLVerification: gitlab.Ptr(h.EnableSSLVerification),
+		}
+		if token, ok := secretsByURL[h.URL]; ok && token != "" {
+			opt.Token = gitlab.Ptr(token)
+		}
+		if _, _, err := client.Projects.AddProjectHook(toProjectID, opt, gitlab.WithContext(ctx)); err != nil {
+			return fmt.Errorf("在目标项目 (ID: %d) 创建 webhook '%s' 失败: %w", toProjectID, h.URL, err)
+		}
+	}
+	return nil
+}
+
+// CopyDeployKeys 将源项目的部署密钥复制到目标项目。部署密钥的公钥内容可以通过 API 读回
+// (与 webhook token 不同，它本身就不是需要保密的一半)，已存在相同公钥的部署密钥会被
+// GitLab 自动关联到同一个 key 而非报错，因此这里不做额外的去重处理。
+func CopyDeployKeys(ctx context.Context, client *gitlab.Client, fromProjectID, toProjectID int) error {
+	keys, _, err := client.DeployKeys.ListProjectDeployKeys(fromProjectID, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("列出源项目 (ID: %d) 的部署密钥失败: %w", fromProjectID, err)
+	}
+	for _, k := range keys {
+		opt := &gitlab.AddDeployKeyOptions{
+			Key:     gitlab.Ptr(k.Key),
+			Title:   gitlab.Ptr(k.Title),
+			CanPush: gitlab.Ptr(k.CanPush),
+		}
+		if _, _, err := client.DeployKeys.AddDeployKey(toProjectID, opt, gitlab.WithContext(ctx)); err != nil {
+			return fmt.Errorf("在目标项目 (ID: %d) 添加部署密钥 '%s' 失败: %w", toProjectID, k.Title, err)
+		}
+	}
+	return nil
+}
+
+// CopyMembers 将源项目的直接成员 (不含继承自所属组的成员) 复制到目标项目
+func CopyMembers(ctx context.Context, client *gitlab.Client, fromProjectID, toProjectID int) error {
+	return CopyMembersFiltered(ctx, client, fromProjectID, toProjectID, gitlab.NoPermissions)
+}
+
+// CopyMembersFiltered 与 CopyMembers 相同，但只复制访问级别不低于 minAccessLevel 的成员，
+// 用于跳过 guest 等低权限成员，minAccessLevel 为 gitlab.NoPermissions 时不做过滤。
+func CopyMembersFiltered(ctx context.Context, client *gitlab.Client, fromProjectID, toProjectID int, minAccessLevel gitlab.AccessLevelValue) error {
+	members, _, err := client.ProjectMembers.ListProjectMembers(fromProjectID, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("列出源项目 (ID: %d) 的成员失败: %w", fromProjectID, err)
+	}
+	for _, m := range members {
+		if m.AccessLevel < minAccessLevel {
+			continue
+		}
+		_, _, err := client.ProjectMembers.AddProjectMember(toProjectID, &gitlab.AddProjectMemberOptions{
+			UserID:      m.ID,
+			AccessLevel: gitlab.Ptr(m.AccessLevel),
+		}, gitlab.WithContext(ctx))
+		if err != nil {
+			if errResp, ok := err.(*gitlab.ErrorResponse); ok && errResp.Response != nil && errResp.Response.StatusCode == 409 {
+				continue // 目标项目已存在该成员 (如已继承自组)，跳过
+			}
+			return fmt.Errorf("在目标项目 (ID: %d) 添加成员 (用户 ID: %d) 失败: %w", toProjectID, m.ID, err)
+		}
+	}
+	return nil
+}
+
+// CopyGroupShares 将源项目共享给的群组 (Project -> Group share) 原样复制到目标项目
+func CopyGroupShares(ctx context.Context, client *gitlab.Client, fromProjectID, toProjectID int) error {
+	source, _, err := client.Projects.GetProject(fromProjectID, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("查询源项目 (ID: %d) 的群组共享失败: %w", fromProjectID, err)
+	}
+	for _, share := range source.SharedWithGroups {
+		opt := &gitlab.ShareWithGroupOptions{
+			GroupID:     gitlab.Ptr(share.GroupID),
+			GroupAccess: gitlab.Ptr(gitlab.AccessLevelValue(share.GroupAccessLevel)),
+		}
+		if _, err := client.Projects.ShareProjectWithGroup(toProjectID, opt, gitlab.WithContext(ctx)); err != nil {
+			if errResp, ok := err.(*gitlab.ErrorResponse); ok && errResp.Response != nil && errResp.Response.StatusCode == 409 {
+				continue // 目标项目已共享给该群组，跳过
+			}
+			return fmt.Errorf("将目标项目 (ID: %d) 共享给群组 '%s' 失败: %w", toProjectID, share.GroupFullPath, err)
+		}
+	}
+	return nil
+}
+
+// CopyProjectBadges 将源项目上的徽章原样复制到目标项目
+func CopyProjectBadges(ctx context.Context, client *gitlab.Client, fromProjectID, toProjectID int) error {
+	badges, _, err := client.ProjectBadges.ListProjectBadges(fromProjectID, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("列出源项目 (ID: %d) 的徽章失败: %w", fromProjectID, err)
+	}
+	for _, b := range badges {
+		_, _, err := client.ProjectBadges.AddProjectBadge(toProjectID, &gitlab.AddProjectBadgeOptions{
+			Name:     gitlab.Ptr(b.Name),
+			LinkURL:  gitlab.Ptr(b.LinkURL),
+			ImageURL: gitlab.Ptr(b.ImageURL),
+		}, gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("在目标项目 (ID: %d) 创建徽章 '%s' 失败: %w", toProjectID, b.Name, err)
+		}
+	}
+	return nil
+}
+
+// CopyMetadata 将源项目的描述与 topics 复制到目标项目，覆盖派生时默认生成的描述/topics
+func CopyMetadata(ctx context.Context, client *gitlab.Client, fromProjectID, toProjectID int) error {
+	source, _, err := client.Projects.GetProject(fromProjectID, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("查询源项目 (ID: %d) 的元数据失败: %w", fromProjectID, err)
+	}
+	if _, _, err := client.Projects.EditProject(toProjectID, &gitlab.EditProjectOptions{
+		Description: gitlab.Ptr(source.Description),
+		Topics:      &source.Topics,
+	}, gitlab.WithContext(ctx)); err != nil {
+		return fmt.Errorf("在目标项目 (ID: %d) 写入元数据失败: %w", toProjectID, err)
+	}
+	return nil
+}
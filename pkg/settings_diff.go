@@ -0,0 +1,145 @@
+package pkg
+
+import "fmt"
+
+// SettingsDiff 是 'diff-settings' 比较两份 SettingsSnapshot 得到的差异，按类别分组，
+// 用于定期审计 dev/prod 等两个项目的配置漂移。
+type SettingsDiff struct {
+	Variables   []FieldDiff `json:"variables,omitempty"`
+	Protections []FieldDiff `json:"protections,omitempty"`
+	Webhooks    []FieldDiff `json:"webhooks,omitempty"`
+	PushRules   []FieldDiff `json:"pushRules,omitempty"`
+	Members     []FieldDiff `json:"members,omitempty"`
+}
+
+// FieldDiff 描述一条差异记录。Status 取值 "removed" (仅源项目存在)、"added" (仅目标项目存在)、
+// "changed" (两边都存在但取值不同)；Source/Target 是各自的可读取值，仅用于展示。
+type FieldDiff struct {
+	Key    string `json:"key"`
+	Status string `json:"status"`
+	Source string `json:"source,omitempty"`
+	Target string `json:"target,omitempty"`
+}
+
+// Empty 判断两份快照在所有类别上是否完全一致
+func (d *SettingsDiff) Empty() bool {
+	return len(d.Variables) == 0 && len(d.Protections) == 0 && len(d.Webhooks) == 0 &&
+		len(d.PushRules) == 0 && len(d.Members) == 0
+}
+
+// DiffSettingsSnapshots 逐类别比较 source 与 target 两份快照，返回它们之间的差异。
+// 变量/受保护分支/webhooks/成员按各自的自然主键 (变量名/分支名/URL/用户名) 对齐比较，
+// push rules 是单个对象，逐字段比较。
+func DiffSettingsSnapshots(source, target *SettingsSnapshot) *SettingsDiff {
+	diff := &SettingsDiff{}
+
+	sourceVars := map[string]VariableSnapshot{}
+	for _, v := range source.Variables {
+		sourceVars[v.Key] = v
+	}
+	targetVars := map[string]VariableSnapshot{}
+	for _, v := range target.Variables {
+		targetVars[v.Key] = v
+	}
+	for key, sv := range sourceVars {
+		if tv, ok := targetVars[key]; !ok {
+			diff.Variables = append(diff.Variables, FieldDiff{Key: key, Status: "removed", Source: variableSummary(sv)})
+		} else if variableSummary(sv) != variableSummary(tv) {
+			diff.Variables = append(diff.Variables, FieldDiff{Key: key, Status: "changed", Source: variableSummary(sv), Target: variableSummary(tv)})
+		}
+	}
+	for key, tv := range targetVars {
+		if _, ok := sourceVars[key]; !ok {
+			diff.Variables = append(diff.Variables, FieldDiff{Key: key, Status: "added", Target: variableSummary(tv)})
+		}
+	}
+
+	sourceProtections := map[string]ProtectedBranchSnapshot{}
+	for _, p := range source.Protections {
+		sourceProtections[p.Name] = p
+	}
+	targetProtections := map[string]ProtectedBranchSnapshot{}
+	for _, p := range target.Protections {
+		targetProtections[p.Name] = p
+	}
+	for name, sp := range sourceProtections {
+		if tp, ok := targetProtections[name]; !ok {
+			diff.Protections = append(diff.Protections, FieldDiff{Key: name, Status: "removed", Source: protectionSummary(sp)})
+		} else if protectionSummary(sp) != protectionSummary(tp) {
+			diff.Protections = append(diff.Protections, FieldDiff{Key: name, Status: "changed", Source: protectionSummary(sp), Target: protectionSummary(tp)})
+		}
+	}
+	for name, tp := range targetProtections {
+		if _, ok := sourceProtections[name]; !ok {
+			diff.Protections = append(diff.Protections, FieldDiff{Key: name, Status: "added", Target: protectionSummary(tp)})
+		}
+	}
+
+	sourceHooks := map[string]WebhookSnapshot{}
+	for _, h := range source.Webhooks {
+		sourceHooks[h.URL] = h
+	}
+	targetHooks := map[string]WebhookSnapshot{}
+	for _, h := range target.Webhooks {
+		targetHooks[h.URL] = h
+	}
+	for url, sh := range sourceHooks {
+		if th, ok := targetHooks[url]; !ok {
+			diff.Webhooks = append(diff.Webhooks, FieldDiff{Key: url, Status: "removed", Source: webhookSummary(sh)})
+		} else if webhookSummary(sh) != webhookSummary(th) {
+			diff.Webhooks = append(diff.Webhooks, FieldDiff{Key: url, Status: "changed", Source: webhookSummary(sh), Target: webhookSummary(th)})
+		}
+	}
+	for url, th := range targetHooks {
+		if _, ok := sourceHooks[url]; !ok {
+			diff.Webhooks = append(diff.Webhooks, FieldDiff{Key: url, Status: "added", Target: webhookSummary(th)})
+		}
+	}
+
+	if sr, tr := pushRuleSummary(source.PushRules), pushRuleSummary(target.PushRules); sr != tr {
+		diff.PushRules = append(diff.PushRules, FieldDiff{Key: "pushRules", Status: "changed", Source: sr, Target: tr})
+	}
+
+	sourceMembers := map[string]MemberSnapshot{}
+	for _, m := range source.Members {
+		sourceMembers[m.Username] = m
+	}
+	targetMembers := map[string]MemberSnapshot{}
+	for _, m := range target.Members {
+		targetMembers[m.Username] = m
+	}
+	for username, sm := range sourceMembers {
+		if tm, ok := targetMembers[username]; !ok {
+			diff.Members = append(diff.Members, FieldDiff{Key: username, Status: "removed", Source: sm.AccessLevel})
+		} else if sm.AccessLevel != tm.AccessLevel {
+			diff.Members = append(diff.Members, FieldDiff{Key: username, Status: "changed", Source: sm.AccessLevel, Target: tm.AccessLevel})
+		}
+	}
+	for username, tm := range targetMembers {
+		if _, ok := sourceMembers[username]; !ok {
+			diff.Members = append(diff.Members, FieldDiff{Key: username, Status: "added", Target: tm.AccessLevel})
+		}
+	}
+
+	return diff
+}
+
+func variableSummary(v VariableSnapshot) string {
+	return fmt.Sprintf("value=%s type=%s protected=%t masked=%t scope=%s", v.Value, v.VariableType, v.Protected, v.Masked, v.EnvironmentScope)
+}
+
+func protectionSummary(p ProtectedBranchSnapshot) string {
+	return fmt.Sprintf("push=%s merge=%s", p.PushAccessLevel, p.MergeAccessLevel)
+}
+
+func webhookSummary(h WebhookSnapshot) string {
+	return fmt.Sprintf("push=%t mr=%t tag=%t pipeline=%t ssl=%t", h.PushEvents, h.MergeRequestsEvents, h.TagPushEvents, h.PipelineEvents, h.EnableSSLVerification)
+}
+
+func pushRuleSummary(r *PushRuleSnapshot) string {
+	if r == nil {
+		return "(未配置)"
+	}
+	return fmt.Sprintf("commitMessageRegex=%s branchNameRegex=%s denyDeleteTag=%t memberCheck=%t preventSecrets=%t fileNameRegex=%s maxFileSize=%d",
+		r.CommitMessageRegex, r.BranchNameRegex, r.DenyDeleteTag, r.MemberCheck, r.PreventSecrets, r.FileNameRegex, r.MaxFileSize)
+}
@@ -0,0 +1,273 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// accessLevelNames 将角色名称映射为 GitLab 的 AccessLevelValue，与 cmd 包中
+// groupAccessLevelByName 保持一致的取值范围，供快照的受保护分支/成员访问级别按可读名称
+// (而不是裸数字) 落盘与解析。
+var accessLevelNames = map[string]gitlab.AccessLevelValue{
+	"guest":      gitlab.GuestPermissions,
+	"reporter":   gitlab.ReporterPermissions,
+	"developer":  gitlab.DeveloperPermissions,
+	"maintainer": gitlab.MaintainerPermissions,
+	"owner":      gitlab.OwnerPermissions,
+}
+
+func accessLevelName(level gitlab.AccessLevelValue) string {
+	for name, lvl := range accessLevelNames {
+		if lvl == level {
+			return name
+		}
+	}
+	return fmt.Sprintf("%d", level)
+}
+
+func accessLevelByName(name string) gitlab.AccessLevelValue {
+	return accessLevelNames[name]
+}
+
+// SettingsSnapshot 是 'export-settings'/'apply-settings' 用来备份、比对、迁移一个项目的
+// 晋级相关设置的可读 YAML 快照：CI/CD 变量、受保护分支、webhooks、push rules 与成员。
+// 与 SettingsProfile (fork 派生时按类别开关直接从源项目复制) 不同，快照落地为文件，
+// 因此可以先离线审阅/diff 再决定是否应用，也可以脱离一次具体的派生操作单独保存。
+type SettingsSnapshot struct {
+	Project     string                    `yaml:"project"`
+	Variables   []VariableSnapshot        `yaml:"variables,omitempty"`
+	Protections []ProtectedBranchSnapshot `yaml:"protections,omitempty"`
+	Webhooks    []WebhookSnapshot         `yaml:"webhooks,omitempty"`
+	PushRules   *PushRuleSnapshot         `yaml:"pushRules,omitempty"`
+	Members     []MemberSnapshot          `yaml:"members,omitempty"`
+}
+
+// VariableSnapshot 记录一个 CI/CD 变量；--redact-secrets 启用时 Value 会被替换为占位符，
+// 因为变量取值往往就是密钥本身，不应无条件落盘到快照文件中。
+type VariableSnapshot struct {
+	Key              string `yaml:"key"`
+	Value            string `yaml:"value"`
+	VariableType     string `yaml:"variableType"`
+	Protected        bool   `yaml:"protected"`
+	Masked           bool   `yaml:"masked"`
+	EnvironmentScope string `yaml:"environmentScope"`
+}
+
+// ProtectedBranchSnapshot 记录一条受保护分支规则
+type ProtectedBranchSnapshot struct {
+	Name             string `yaml:"name"`
+	PushAccessLevel  string `yaml:"pushAccessLevel,omitempty"`
+	MergeAccessLevel string `yaml:"mergeAccessLevel,omitempty"`
+}
+
+// WebhookSnapshot 记录一个 webhook
+type WebhookSnapshot struct {
+	URL                   string `yaml:"url"`
+	PushEvents            bool   `yaml:"pushEvents"`
+	MergeRequestsEvents   bool   `yaml:"mergeRequestsEvents"`
+	TagPushEvents         bool   `yaml:"tagPushEvents"`
+	PipelineEvents        bool   `yaml:"pipelineEvents"`
+	EnableSSLVerification bool   `yaml:"enableSslVerification"`
+}
+
+// PushRuleSnapshot 记录项目的 push rules
+type PushRuleSnapshot struct {
+	CommitMessageRegex string `yaml:"commitMessageRegex,omitempty"`
+	BranchNameRegex    string `yaml:"branchNameRegex,omitempty"`
+	DenyDeleteTag      bool   `yaml:"denyDeleteTag,omitempty"`
+	MemberCheck        bool   `yaml:"memberCheck,omitempty"`
+	PreventSecrets     bool   `yaml:"preventSecrets,omitempty"`
+	FileNameRegex      string `yaml:"fileNameRegex,omitempty"`
+	MaxFileSize        int    `yaml:"maxFileSize,omitempty"`
+}
+
+// MemberSnapshot 记录一个项目直接成员
+type MemberSnapshot struct {
+	Username    string `yaml:"username"`
+	AccessLevel string `yaml:"accessLevel"`
+}
+
+// ExportSettingsSnapshot 从项目读取变量、受保护分支、webhooks、push rules 与成员，汇总为一份快照。
+// redactSecrets 为 true 时变量取值一律替换为 "***" 占位符，仅保留变量名/类型/作用域，
+// 适用于快照需要提交到版本库审阅、而不希望明文密钥随之落盘的场景。
+func ExportSettingsSnapshot(ctx context.Context, client *gitlab.Client, projectID int, projectPath string, redactSecrets bool) (*SettingsSnapshot, error) {
+	snapshot := &SettingsSnapshot{Project: projectPath}
+
+	variables, _, err := client.ProjectVariables.ListVariables(projectID, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("列出项目 (ID: %d) 的 CI/CD 变量失败: %w", projectID, err)
+	}
+	for _, v := range variables {
+		value := v.Value
+		if redactSecrets {
+			value = "***"
+		}
+		snapshot.Variables = append(snapshot.Variables, VariableSnapshot{
+			Key:              v.Key,
+			Value:            value,
+			VariableType:     string(v.VariableType),
+			Protected:        v.Protected,
+			Masked:           v.Masked,
+			EnvironmentScope: v.EnvironmentScope,
+		})
+	}
+
+	branches, _, err := client.ProtectedBranches.ListProtectedBranches(projectID, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("列出项目 (ID: %d) 的受保护分支失败: %w", projectID, err)
+	}
+	for _, b := range branches {
+		p := ProtectedBranchSnapshot{Name: b.Name}
+		if len(b.PushAccessLevels) > 0 {
+			p.PushAccessLevel = accessLevelName(b.PushAccessLevels[0].AccessLevel)
+		}
+		if len(b.MergeAccessLevels) > 0 {
+			p.MergeAccessLevel = accessLevelName(b.MergeAccessLevels[0].AccessLevel)
+		}
+		snapshot.Protections = append(snapshot.Protections, p)
+	}
+
+	hooks, _, err := client.Projects.ListProjectHooks(projectID, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("列出项目 (ID: %d) 的 webhooks 失败: %w", projectID, err)
+	}
+	for _, h := range hooks {
+		snapshot.Webhooks = append(snapshot.Webhooks, WebhookSnapshot{
+			URL:                   h.URL,
+			PushEvents:            h.PushEvents,
+			MergeRequestsEvents:   h.MergeRequestsEvents,
+			TagPushEvents:         h.TagPushEvents,
+			PipelineEvents:        h.PipelineEvents,
+			EnableSSLVerification: h.EnableSSLVerification,
+		})
+	}
+
+	pushRules, _, err := client.Projects.GetProjectPushRules(projectID, gitlab.WithContext(ctx))
+	if err != nil {
+		if errResp, ok := err.(*gitlab.ErrorResponse); !ok || errResp.Response == nil || errResp.Response.StatusCode != 404 {
+			return nil, fmt.Errorf("查询项目 (ID: %d) 的 push rules 失败: %w", projectID, err)
+		}
+		// 404: 项目未配置 push rules，快照中留空
+	} else if pushRules != nil {
+		snapshot.PushRules = &PushRuleSnapshot{
+			CommitMessageRegex: pushRules.CommitMessageRegex,
+			BranchNameRegex:    pushRules.BranchNameRegex,
+			DenyDeleteTag:      pushRules.DenyDeleteTag,
+			MemberCheck:        pushRules.MemberCheck,
+			PreventSecrets:     pushRules.PreventSecrets,
+			FileNameRegex:      pushRules.FileNameRegex,
+			MaxFileSize:        pushRules.MaxFileSize,
+		}
+	}
+
+	members, _, err := client.ProjectMembers.ListProjectMembers(projectID, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("列出项目 (ID: %d) 的成员失败: %w", projectID, err)
+	}
+	for _, m := range members {
+		snapshot.Members = append(snapshot.Members, MemberSnapshot{
+			Username:    m.Username,
+			AccessLevel: accessLevelName(m.AccessLevel),
+		})
+	}
+
+	return snapshot, nil
+}
+
+// ApplySettingsSnapshot 将一份快照应用到目标项目：已存在的变量/成员按 key/username 跳过而不覆盖，
+// 受保护分支/webhooks/push rules 只做创建、不覆盖目标项目已有的配置，失败的条目 (含因已存在而创建失败的)
+// 记录为警告后继续处理其余条目。
+func ApplySettingsSnapshot(ctx context.Context, client *gitlab.Client, projectID int, snapshot *SettingsSnapshot, warnings *WarningCollector) error {
+	for _, v := range snapshot.Variables {
+		_, _, err := client.ProjectVariables.CreateVariable(projectID, &gitlab.CreateProjectVariableOptions{
+			Key:              gitlab.Ptr(v.Key),
+			Value:            gitlab.Ptr(v.Value),
+			VariableType:     gitlab.Ptr(gitlab.VariableTypeValue(v.VariableType)),
+			Protected:        gitlab.Ptr(v.Protected),
+			Masked:           gitlab.Ptr(v.Masked),
+			EnvironmentScope: gitlab.Ptr(v.EnvironmentScope),
+		}, gitlab.WithContext(ctx))
+		if err != nil {
+			if errResp, ok := err.(*gitlab.ErrorResponse); ok && errResp.Response != nil && errResp.Response.StatusCode == 400 {
+				continue // 目标项目已存在同名变量 (同一环境作用域)，跳过而不覆盖
+			}
+			if wrapErr := recordOrReturn(warnings, "settings-snapshot-variable-failed", "应用变量 '%s' 失败: %v", v.Key, err); wrapErr != nil {
+				return wrapErr
+			}
+		}
+	}
+
+	for _, p := range snapshot.Protections {
+		opt := &gitlab.ProtectRepositoryBranchesOptions{Name: gitlab.Ptr(p.Name)}
+		if p.PushAccessLevel != "" {
+			opt.PushAccessLevel = gitlab.Ptr(accessLevelByName(p.PushAccessLevel))
+		}
+		if p.MergeAccessLevel != "" {
+			opt.MergeAccessLevel = gitlab.Ptr(accessLevelByName(p.MergeAccessLevel))
+		}
+		if _, _, err := client.ProtectedBranches.ProtectRepositoryBranches(projectID, opt, gitlab.WithContext(ctx)); err != nil {
+			if wrapErr := recordOrReturn(warnings, "settings-snapshot-protection-failed", "应用受保护分支 '%s' 失败: %v", p.Name, err); wrapErr != nil {
+				return wrapErr
+			}
+		}
+	}
+
+	for _, h := range snapshot.Webhooks {
+		opt := &gitlab.AddProjectHookOptions{
+			URL:                   gitlab.Ptr(h.URL),
+			PushEvents:            gitlab.Ptr(h.PushEvents),
+			MergeRequestsEvents:   gitlab.Ptr(h.MergeRequestsEvents),
+			TagPushEvents:         gitlab.Ptr(h.TagPushEvents),
+			PipelineEvents:        gitlab.Ptr(h.PipelineEvents),
+			EnableSSLVerification: gitlab.Ptr(h.EnableSSLVerification),
+		}
+		if _, _, err := client.Projects.AddProjectHook(projectID, opt, gitlab.WithContext(ctx)); err != nil {
+			if wrapErr := recordOrReturn(warnings, "settings-snapshot-webhook-failed", "应用 webhook '%s' 失败: %v", h.URL, err); wrapErr != nil {
+				return wrapErr
+			}
+		}
+	}
+
+	if snapshot.PushRules != nil {
+		opt := &gitlab.AddProjectPushRuleOptions{
+			CommitMessageRegex: gitlab.Ptr(snapshot.PushRules.CommitMessageRegex),
+			BranchNameRegex:    gitlab.Ptr(snapshot.PushRules.BranchNameRegex),
+			DenyDeleteTag:      gitlab.Ptr(snapshot.PushRules.DenyDeleteTag),
+			MemberCheck:        gitlab.Ptr(snapshot.PushRules.MemberCheck),
+			PreventSecrets:     gitlab.Ptr(snapshot.PushRules.PreventSecrets),
+			FileNameRegex:      gitlab.Ptr(snapshot.PushRules.FileNameRegex),
+			MaxFileSize:        gitlab.Ptr(snapshot.PushRules.MaxFileSize),
+		}
+		if _, _, err := client.Projects.AddProjectPushRule(projectID, opt, gitlab.WithContext(ctx)); err != nil {
+			if wrapErr := recordOrReturn(warnings, "settings-snapshot-pushrules-failed", "应用 push rules 失败: %v", err); wrapErr != nil {
+				return wrapErr
+			}
+		}
+	}
+
+	for _, m := range snapshot.Members {
+		user, _, err := client.Users.ListUsers(&gitlab.ListUsersOptions{Username: gitlab.Ptr(m.Username)}, gitlab.WithContext(ctx))
+		if err != nil || len(user) == 0 {
+			if wrapErr := recordOrReturn(warnings, "settings-snapshot-member-failed", "解析用户名 '%s' 失败，跳过该成员: %v", m.Username, err); wrapErr != nil {
+				return wrapErr
+			}
+			continue
+		}
+		_, _, err = client.ProjectMembers.AddProjectMember(projectID, &gitlab.AddProjectMemberOptions{
+			UserID:      user[0].ID,
+			AccessLevel: gitlab.Ptr(accessLevelByName(m.AccessLevel)),
+		}, gitlab.WithContext(ctx))
+		if err != nil {
+			if errResp, ok := err.(*gitlab.ErrorResponse); ok && errResp.Response != nil && errResp.Response.StatusCode == 409 {
+				continue // 目标项目已存在该成员 (如已继承自组)，跳过
+			}
+			if wrapErr := recordOrReturn(warnings, "settings-snapshot-member-failed", "添加成员 '%s' 失败: %v", m.Username, err); wrapErr != nil {
+				return wrapErr
+			}
+		}
+	}
+
+	return nil
+}
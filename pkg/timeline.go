@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"fmt"
+	"time"
+)
+
+// PhaseRecord 记录一个阶段的名称与耗时
+type PhaseRecord struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Timeline 按顺序记录多阶段操作 (resolve/preflight/fork/wait/clone/push/verify 等) 的耗时，
+// 便于在多分钟级的批量派生/推广流程中定位时间消耗集中在哪个阶段。
+type Timeline struct {
+	phases       []PhaseRecord
+	currentName  string
+	currentStart time.Time
+}
+
+// NewTimeline 创建一个空的 Timeline
+func NewTimeline() *Timeline {
+	return &Timeline{}
+}
+
+// StartPhase 结束当前阶段（如果有）并开始一个新阶段
+func (t *Timeline) StartPhase(name string) {
+	t.EndPhase()
+	t.currentName = name
+	t.currentStart = time.Now()
+}
+
+// EndPhase 结束当前阶段并记录其耗时，重复调用或没有进行中的阶段时不做任何事
+func (t *Timeline) EndPhase() {
+	if t.currentName == "" {
+		return
+	}
+	t.phases = append(t.phases, PhaseRecord{Name: t.currentName, Duration: time.Since(t.currentStart)})
+	t.currentName = ""
+}
+
+// Phases 返回已完成阶段的副本
+func (t *Timeline) Phases() []PhaseRecord {
+	return append([]PhaseRecord{}, t.phases...)
+}
+
+// PrintSummary 打印各阶段耗时及总耗时，没有任何阶段时不输出内容
+func (t *Timeline) PrintSummary() {
+	t.EndPhase()
+	if len(t.phases) == 0 {
+		return
+	}
+	fmt.Println("\n⏱️  操作时间线:")
+	var total time.Duration
+	for _, p := range t.phases {
+		fmt.Printf("  - %-10s %v\n", p.Name, p.Duration.Round(time.Millisecond))
+		total += p.Duration
+	}
+	fmt.Printf("  合计: %v\n", total.Round(time.Millisecond))
+}
@@ -0,0 +1,80 @@
+package pkg
+
+import "fmt"
+
+// Warning 描述一次运行过程中的非致命问题
+type Warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// WarningCollector 收集运行过程中散落各处的非致命问题（跳过的标签、缺失的可选配置、
+// 回退行为、TLS 校验被关闭等），以便在运行结束时统一打印，避免被日志淹没而被忽略。
+type WarningCollector struct {
+	warnings      []Warning
+	strict        bool
+	correlationID string
+}
+
+// NewWarningCollector 创建一个空的 WarningCollector
+func NewWarningCollector() *WarningCollector {
+	return &WarningCollector{}
+}
+
+// strictCodes 列出在 --strict 模式下会被提升为错误的警告分类，用于要求"完全干净运行"的合规环境
+var strictCodes = map[string]bool{
+	"insecure-tls":          true,
+	"default-admin-ns":      true,
+	"tag-skipped":           true,
+	"settings-copy-partial": true,
+}
+
+// SetStrict 开启/关闭 strict 模式
+func (c *WarningCollector) SetStrict(strict bool) {
+	c.strict = strict
+}
+
+// SetCorrelationID 记录本次运行的关联 ID，非空时会被打印在 PrintSummary 的汇总报告中，
+// 便于在流水线日志中直接从最终报告跳转回 GitLab/k8s 侧记录的同一个 ID
+func (c *WarningCollector) SetCorrelationID(id string) {
+	c.correlationID = id
+}
+
+// Add 记录一条带有分类 code 的警告；若已启用 strict 模式且该分类被视为严重问题，
+// 返回一个 error，调用方应将其当作致命错误处理而不是继续执行
+func (c *WarningCollector) Add(code, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	c.warnings = append(c.warnings, Warning{Code: code, Message: msg})
+	if c.strict && strictCodes[code] {
+		return fmt.Errorf("[strict] 警告 '%s' 在 --strict 模式下被视为错误: %s", code, msg)
+	}
+	return nil
+}
+
+// Warnings 返回当前已收集的全部警告（副本）
+func (c *WarningCollector) Warnings() []Warning {
+	return append([]Warning{}, c.warnings...)
+}
+
+// HasWarnings 判断是否存在任何警告
+func (c *WarningCollector) HasWarnings() bool {
+	return len(c.warnings) > 0
+}
+
+// PrintSummary 在标准输出打印本次运行的关联 ID (若已设置) 与汇总的警告信息块；
+// 关联 ID 未设置且没有警告时不输出任何内容。
+func (c *WarningCollector) PrintSummary() {
+	if c.correlationID == "" && len(c.warnings) == 0 {
+		return
+	}
+	if c.correlationID != "" {
+		fmt.Printf("\nℹ️  correlation-id: %s\n", c.correlationID)
+	}
+	if len(c.warnings) == 0 {
+		return
+	}
+	fmt.Printf("\n⚠️  警告汇总 (共 %d 条):\n", len(c.warnings))
+	for i, w := range c.warnings {
+		fmt.Printf("  %d. [%s] %s\n", i+1, w.Code, w.Message)
+	}
+}